@@ -1,8 +1,76 @@
 // config/config.go
 package config
 
+import "time"
+
+// DefaultOneStepGPSBaseURL is the public v3 API root used when no override is configured.
+const DefaultOneStepGPSBaseURL = "https://track.onestepgps.com/v3/api/public"
+
 type Config struct {
-	OneStepGPSAPIKey string
-	GoogleMapsAPIKey string
-	DSN              string
-}
\ No newline at end of file
+	OneStepGPSAPIKey  string
+	OneStepGPSBaseURL string
+	// OneStepGPSFixture, when set, points at a devices.json/all.json style file
+	// that is served in place of the upstream API.
+	OneStepGPSFixture string
+	GoogleMapsAPIKey  string
+	DSN               string
+	// AllowedOrigins are the browser origins accepted by CORS and WebSocket upgrades.
+	AllowedOrigins []string
+
+	// Retry policy for idempotent upstream GETs.
+	UpstreamMaxRetries     int
+	UpstreamRetryBaseDelay time.Duration
+	UpstreamRetryMaxDelay  time.Duration
+
+	// DeviceCacheTTL is how long a device list is served without refreshing;
+	// zero disables caching. DeviceCacheMaxStale is how long past the TTL the
+	// old list may still be served while a refresh runs in the background.
+	DeviceCacheTTL      time.Duration
+	DeviceCacheMaxStale time.Duration
+
+	// IngestInterval is how often the device list is polled and stored;
+	// zero disables ingestion.
+	IngestInterval time.Duration
+
+	// StreamBacklog is how many device change events are kept for clients
+	// resuming a live stream.
+	StreamBacklog int
+
+	// GeofenceMargin is the base hysteresis band in meters a point must be
+	// past a geofence edge to count as an enter or exit. GeofenceDwell is how
+	// long a device must stay inside before a dwell event is emitted.
+	GeofenceMargin float64
+	GeofenceDwell  time.Duration
+
+	// Circuit breaker guarding the upstream.
+	BreakerFailureThreshold int
+	BreakerCooldown         time.Duration
+
+	// SMTP relay for email notifications; email is disabled when SMTPHost is empty.
+	SMTPHost     string
+	SMTPPort     int
+	SMTPUsername string
+	SMTPPassword string
+	SMTPFrom     string
+
+	// EmailDigestInterval is how often digest subscribers receive batched
+	// alerts. EmailBurstLimit is how many alerts an immediate subscriber
+	// receives individually per flush before they are sent as one digest.
+	EmailDigestInterval time.Duration
+	EmailBurstLimit     int
+
+	// Geocoder selects the reverse geocoding provider: "google", "offline",
+	// "stub" or "none". Empty uses Google when GoogleMapsAPIKey is set.
+	Geocoder string
+	// GeocoderDataset is a GeoNames cities file for the offline provider;
+	// empty uses the bundled sample. GeocodeMaxDistance is how far in meters
+	// a point may be from the nearest place and still resolve to it.
+	GeocoderDataset    string
+	GeocodeMaxDistance float64
+	// GeocodeRateLimit caps provider requests per second. Results are cached
+	// for GeocodeCacheTTL, keyed on coordinates rounded to GeocodePrecision
+	// decimal places.
+	GeocodeRateLimit float64
+	GeocodePrecision int
+	GeocodeCacheTTL  time.Duration
+}
@@ -0,0 +1,313 @@
+// Package drivestop splits a device's stored points into drive, idle and stop
+// segments, producing the same shape as the OneStepGPS route/drive-stop
+// endpoint so history can be analysed without the upstream.
+package drivestop
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/alexbeattie/golangone/geo"
+	"github.com/alexbeattie/golangone/models"
+)
+
+// Segment types.
+const (
+	TypeDrive = "drive"
+	TypeIdle  = "idle"
+	TypeStop  = "stop"
+)
+
+// Options tune a computation independently of the device settings.
+type Options struct {
+	// MinStopDuration folds idles and stops shorter than this into the
+	// neighbouring drive, like the upstream stop_duration parameter.
+	MinStopDuration time.Duration
+	// Imperial reports distances in miles and speeds in mph instead of km and km/h.
+	Imperial bool
+}
+
+// Compute segments points, which must belong to one device and be ordered by
+// DtTracker. from and to are echoed as the response time window.
+func Compute(points []models.StoredDevicePoint, from, to time.Time, settings Settings, opts Options) *models.DriveStopResponse {
+	seg := NewSegmenter(settings)
+	for _, p := range points {
+		seg.Add(p)
+	}
+	return seg.Result(from, to, opts)
+}
+
+// segment is a run of points of one type. A drive also covers the first point
+// of the following segment so the distance across the boundary is counted.
+// bridge and bridgeTop hold the distance to, and top speed of, points between
+// this segment's last point and the next kept segment's first point; they only
+// count if the two end up merged.
+type segment struct {
+	typ         string
+	from, to    time.Time
+	first, last models.StoredDevicePoint
+	dist, top   float64
+	bridge      float64
+	bridgeTop   float64
+}
+
+func newSegment(typ string, p models.StoredDevicePoint) segment {
+	return segment{typ: typ, from: p.DtTracker, first: p, last: p, top: p.Speed}
+}
+
+// extend adds p to the segment, travelling from the segment's last point.
+func (seg *segment) extend(p models.StoredDevicePoint) {
+	seg.dist += geo.DistanceMeters(seg.last.Lat, seg.last.Lng, p.Lat, p.Lng)
+	seg.top = math.Max(seg.top, p.Speed)
+	seg.last = p
+}
+
+// Segmenter splits points fed one at a time, keeping only per-segment state
+// so arbitrarily long histories can be segmented while they are streamed.
+type Segmenter struct {
+	settings Settings
+	moving   bool
+	started  bool
+	prev     models.StoredDevicePoint
+	cur      segment
+	segments []segment
+}
+
+func NewSegmenter(settings Settings) *Segmenter {
+	return &Segmenter{settings: settings}
+}
+
+// classify returns the segment type for p. Movement has hysteresis between
+// the begin-moving and begin-stopped speeds.
+func (s *Segmenter) classify(p models.StoredDevicePoint) string {
+	switch {
+	case p.Speed >= s.settings.BeginMovingSpeed && p.Speed > 0:
+		s.moving = true
+	case p.Speed <= s.settings.BeginStoppedSpeed:
+		s.moving = false
+	}
+	if s.moving {
+		return TypeDrive
+	}
+	if p.Acc {
+		return TypeIdle
+	}
+	return TypeStop
+}
+
+// Add feeds the next point, which must not be older than the previous one.
+// A new segment is cut whenever the class changes or a drive goes silent
+// for longer than the drive timeout.
+func (s *Segmenter) Add(p models.StoredDevicePoint) {
+	typ := s.classify(p)
+	if !s.started {
+		s.started, s.prev, s.cur = true, p, newSegment(typ, p)
+		return
+	}
+	prev := s.prev
+	s.prev = p
+
+	if s.cur.typ == TypeDrive && s.settings.DriveTimeout > 0 && p.DtTracker.Sub(prev.DtTracker) > s.settings.DriveTimeout {
+		s.cur.to = prev.DtTracker
+		gap := segment{typ: TypeStop, from: prev.DtTracker, to: p.DtTracker, first: prev, last: prev, top: prev.Speed}
+		gap.bridge = geo.DistanceMeters(prev.Lat, prev.Lng, p.Lat, p.Lng)
+		s.segments = append(s.segments, s.cur, gap)
+		s.cur = newSegment(typ, p)
+		return
+	}
+
+	if typ == s.cur.typ {
+		s.cur.extend(p)
+		return
+	}
+
+	s.cur.to = p.DtTracker
+	if s.cur.typ == TypeDrive {
+		s.cur.extend(p)
+	} else {
+		s.cur.bridge = geo.DistanceMeters(prev.Lat, prev.Lng, p.Lat, p.Lng)
+	}
+	s.segments = append(s.segments, s.cur)
+	s.cur = newSegment(typ, p)
+}
+
+// Result builds the response for the points added so far. from and to are
+// echoed as the response time window.
+func (s *Segmenter) Result(from, to time.Time, opts Options) *models.DriveStopResponse {
+	resp := &models.DriveStopResponse{
+		TimeFrom:      from.Format(time.RFC3339),
+		TimeTo:        to.Format(time.RFC3339),
+		DriveStopList: []models.DriveStopPoint{},
+	}
+
+	var segments []segment
+	if s.started {
+		last := s.cur
+		last.to = s.prev.DtTracker
+		segments = make([]segment, 0, len(s.segments)+1)
+		segments = append(segments, s.segments...)
+		segments = append(segments, last)
+	}
+	segments = merge(fold(segments, s.settings, opts))
+
+	var distance, topSpeed float64
+	var driveTime, idleTime, stopTime time.Duration
+	for _, seg := range segments {
+		dur := seg.to.Sub(seg.from)
+
+		switch seg.typ {
+		case TypeDrive:
+			distance += seg.dist
+			topSpeed = math.Max(topSpeed, seg.top)
+			driveTime += dur
+		case TypeIdle:
+			idleTime += dur
+		case TypeStop:
+			stopTime += dur
+		}
+
+		resp.DriveStopList = append(resp.DriveStopList, toDriveStopPoint(seg, opts))
+	}
+
+	if len(segments) > 0 {
+		resp.Duration = durationData(segments[len(segments)-1].to.Sub(segments[0].from))
+	} else {
+		resp.Duration = durationData(0)
+	}
+	resp.Distance = distanceData(distance, opts)
+	resp.AverageSpeed = speedData(averageSpeed(distance, driveTime), opts)
+	resp.TopSpeed = speedData(topSpeed, opts)
+	resp.IdleDuration = durationData(idleTime)
+	resp.StopDuration = durationData(stopTime)
+	return resp
+}
+
+// fold applies the stop timeout and minimum stop duration.
+func fold(segments []segment, settings Settings, opts Options) []segment {
+	for i := range segments {
+		seg := &segments[i]
+		dur := seg.to.Sub(seg.from)
+
+		if seg.typ == TypeIdle && settings.StopTimeout > 0 && dur > settings.StopTimeout {
+			seg.typ = TypeStop
+		}
+
+		if seg.typ != TypeDrive && dur < opts.MinStopDuration {
+			prevDrive := i > 0 && segments[i-1].typ == TypeDrive
+			nextDrive := i+1 < len(segments) && segments[i+1].typ == TypeDrive
+			if prevDrive || nextDrive {
+				seg.typ = TypeDrive
+			}
+		}
+	}
+	return segments
+}
+
+// merge drops zero-length segments, which come from single isolated points,
+// and joins adjacent segments of the same type. A dropped segment's travel
+// is carried in the previous segment's bridge.
+func merge(segments []segment) []segment {
+	var out []segment
+	for _, seg := range segments {
+		n := len(out)
+		if !seg.to.After(seg.from) && len(segments) > 1 {
+			if n > 0 {
+				out[n-1].bridge += seg.dist + seg.bridge
+				out[n-1].bridgeTop = math.Max(out[n-1].bridgeTop, seg.top)
+			}
+			continue
+		}
+		if n > 0 && out[n-1].typ == seg.typ {
+			o := &out[n-1]
+			o.dist += o.bridge + seg.dist
+			o.top = math.Max(o.top, math.Max(o.bridgeTop, seg.top))
+			o.bridge, o.bridgeTop = seg.bridge, seg.bridgeTop
+			o.last, o.to = seg.last, seg.to
+			continue
+		}
+		out = append(out, seg)
+	}
+	return out
+}
+
+func toDriveStopPoint(seg segment, opts Options) models.DriveStopPoint {
+	first, last := seg.first, seg.last
+
+	dsp := models.DriveStopPoint{
+		Type:        seg.typ,
+		Duration:    durationData(seg.to.Sub(seg.from)),
+		FirstLatLng: models.LatLng{Lat: first.Lat, Lng: first.Lng},
+		LastLatLng:  models.LatLng{Lat: last.Lat, Lng: last.Lng},
+		TimeFrom:    seg.from.Format(time.RFC3339),
+		TimeTo:      seg.to.Format(time.RFC3339),
+	}
+	dsp.OdometerFrom = odometerData(first)
+	dsp.OdometerTo = odometerData(last)
+
+	if seg.typ == TypeDrive {
+		distance := distanceData(seg.dist, opts)
+		average := speedData(averageSpeed(seg.dist, seg.to.Sub(seg.from)), opts)
+		topSpeed := speedData(seg.top, opts)
+		dsp.Distance, dsp.AverageSpeed, dsp.TopSpeed = &distance, &average, &topSpeed
+	}
+	return dsp
+}
+
+// averageSpeed converts meters over a duration to km/h.
+func averageSpeed(meters float64, d time.Duration) float64 {
+	if d <= 0 {
+		return 0
+	}
+	return meters / 1000 / d.Hours()
+}
+
+func durationData(d time.Duration) models.DurationData {
+	d = d.Round(time.Second)
+	return models.DurationData{Value: d.Seconds(), Unit: "s", Display: formatDuration(d)}
+}
+
+func distanceData(meters float64, opts Options) models.DurationData {
+	km := meters / 1000
+	if opts.Imperial {
+		mi := km / geo.KmPerMile
+		return models.DurationData{Value: mi, Unit: "mi", Display: fmt.Sprintf("%.1f mi", mi)}
+	}
+	return models.DurationData{Value: km, Unit: "km", Display: fmt.Sprintf("%.1f km", km)}
+}
+
+func speedData(kph float64, opts Options) models.DurationData {
+	if opts.Imperial {
+		mph := kph / geo.KmPerMile
+		return models.DurationData{Value: mph, Unit: "mph", Display: fmt.Sprintf("%.0f mph", mph)}
+	}
+	return models.DurationData{Value: kph, Unit: "km/h", Display: fmt.Sprintf("%.0f km/h", kph)}
+}
+
+func odometerData(p models.StoredDevicePoint) models.DurationData {
+	return models.DurationData{
+		Value:   p.Odometer,
+		Unit:    p.OdometerUnit,
+		Display: fmt.Sprintf("%.1f %s", p.Odometer, p.OdometerUnit),
+	}
+}
+
+// formatDuration renders d like the upstream does, e.g. "1h 5m" or "30m 28s".
+func formatDuration(d time.Duration) string {
+	h := int(d.Hours())
+	m := int(d.Minutes()) % 60
+	s := int(d.Seconds()) % 60
+
+	switch {
+	case h > 0 && m > 0:
+		return fmt.Sprintf("%dh %dm", h, m)
+	case h > 0:
+		return fmt.Sprintf("%dh", h)
+	case m > 0 && s > 0:
+		return fmt.Sprintf("%dm %ds", m, s)
+	case m > 0:
+		return fmt.Sprintf("%dm", m)
+	default:
+		return fmt.Sprintf("%ds", s)
+	}
+}
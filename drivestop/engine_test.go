@@ -0,0 +1,176 @@
+package drivestop
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/alexbeattie/golangone/models"
+)
+
+var t0 = time.Date(2026, 3, 2, 8, 0, 0, 0, time.UTC)
+
+// at returns a point min minutes after t0. Points don't move, so only the
+// segment types and durations are under test.
+func at(min int, speed float64, acc bool) models.StoredDevicePoint {
+	return models.StoredDevicePoint{
+		DeviceID:  "d1",
+		DtTracker: t0.Add(time.Duration(min) * time.Minute),
+		Speed:     speed,
+		Acc:       acc,
+	}
+}
+
+type span struct {
+	Type    string
+	Minutes float64
+}
+
+func spans(resp *models.DriveStopResponse) []span {
+	out := []span{}
+	for _, dsp := range resp.DriveStopList {
+		out = append(out, span{dsp.Type, dsp.Duration.Value / 60})
+	}
+	return out
+}
+
+func TestCompute(t *testing.T) {
+	settings := Settings{BeginMovingSpeed: 10, BeginStoppedSpeed: 2}
+
+	tests := []struct {
+		name     string
+		settings Settings
+		opts     Options
+		points   []models.StoredDevicePoint
+		want     []span
+	}{
+		{
+			name:     "hysteresis keeps a drive between the thresholds",
+			settings: settings,
+			points: []models.StoredDevicePoint{
+				at(0, 0, true), at(5, 12, true), at(10, 5, true), at(15, 5, true), at(20, 1, true), at(25, 1, true),
+			},
+			want: []span{{TypeIdle, 5}, {TypeDrive, 15}, {TypeIdle, 5}},
+		},
+		{
+			name:     "hysteresis does not start a drive below the moving speed",
+			settings: settings,
+			points:   []models.StoredDevicePoint{at(0, 0, true), at(5, 5, true), at(10, 8, true)},
+			want:     []span{{TypeIdle, 10}},
+		},
+		{
+			name:     "ignition off is a stop",
+			settings: settings,
+			points:   []models.StoredDevicePoint{at(0, 20, true), at(5, 0, false), at(20, 0, false)},
+			want:     []span{{TypeDrive, 5}, {TypeStop, 15}},
+		},
+		{
+			name:     "drive timeout cuts a silent drive with a stop",
+			settings: Settings{BeginMovingSpeed: 10, DriveTimeout: 10 * time.Minute},
+			points:   []models.StoredDevicePoint{at(0, 20, true), at(5, 20, true), at(30, 20, true), at(35, 20, true)},
+			want:     []span{{TypeDrive, 5}, {TypeStop, 25}, {TypeDrive, 5}},
+		},
+		{
+			name:     "gap within the drive timeout continues the drive",
+			settings: Settings{BeginMovingSpeed: 10, DriveTimeout: 30 * time.Minute},
+			points:   []models.StoredDevicePoint{at(0, 20, true), at(5, 20, true), at(30, 20, true), at(35, 20, true)},
+			want:     []span{{TypeDrive, 35}},
+		},
+		{
+			name:     "stop timeout turns a long idle into a stop",
+			settings: Settings{BeginMovingSpeed: 10, StopTimeout: 10 * time.Minute},
+			points:   []models.StoredDevicePoint{at(0, 20, true), at(5, 0, true), at(25, 0, true), at(30, 20, true), at(35, 20, true)},
+			want:     []span{{TypeDrive, 5}, {TypeStop, 25}, {TypeDrive, 5}},
+		},
+		{
+			name:     "short idle between drives folds into one drive",
+			settings: settings,
+			opts:     Options{MinStopDuration: 5 * time.Minute},
+			points:   []models.StoredDevicePoint{at(0, 20, true), at(5, 0, true), at(8, 20, true), at(13, 20, true)},
+			want:     []span{{TypeDrive, 13}},
+		},
+		{
+			name:     "idle at least the minimum stays",
+			settings: settings,
+			opts:     Options{MinStopDuration: 5 * time.Minute},
+			points:   []models.StoredDevicePoint{at(0, 20, true), at(5, 0, true), at(10, 20, true), at(15, 20, true)},
+			want:     []span{{TypeDrive, 5}, {TypeIdle, 5}, {TypeDrive, 5}},
+		},
+		{
+			name:     "short idle with no drive beside it stays",
+			settings: settings,
+			opts:     Options{MinStopDuration: 5 * time.Minute},
+			points:   []models.StoredDevicePoint{at(0, 0, true), at(3, 0, false), at(20, 0, false)},
+			want:     []span{{TypeIdle, 3}, {TypeStop, 17}},
+		},
+		{
+			name:     "no points",
+			settings: settings,
+			want:     []span{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := Compute(tt.points, t0, t0.Add(time.Hour), tt.settings, tt.opts)
+			if got := spans(resp); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("segments = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMerge(t *testing.T) {
+	seg := func(typ string, from, to int, dist, bridge, top float64) segment {
+		return segment{
+			typ:    typ,
+			from:   t0.Add(time.Duration(from) * time.Minute),
+			to:     t0.Add(time.Duration(to) * time.Minute),
+			dist:   dist,
+			bridge: bridge,
+			top:    top,
+		}
+	}
+
+	tests := []struct {
+		name string
+		in   []segment
+		want []segment
+	}{
+		{
+			name: "adjacent drives join across their bridge",
+			in:   []segment{seg(TypeDrive, 0, 5, 100, 10, 30), seg(TypeDrive, 5, 10, 200, 0, 50)},
+			want: []segment{seg(TypeDrive, 0, 10, 310, 0, 50)},
+		},
+		{
+			name: "zero-length segment is dropped and its travel carried",
+			in: []segment{
+				seg(TypeDrive, 0, 5, 100, 0, 30),
+				seg(TypeIdle, 5, 5, 0, 50, 70),
+				seg(TypeDrive, 5, 10, 200, 0, 40),
+			},
+			want: []segment{{
+				typ: TypeDrive, from: t0, to: t0.Add(10 * time.Minute),
+				dist: 350, top: 70,
+			}},
+		},
+		{
+			name: "different types stay apart",
+			in:   []segment{seg(TypeDrive, 0, 5, 100, 0, 30), seg(TypeStop, 5, 10, 0, 0, 0)},
+			want: []segment{seg(TypeDrive, 0, 5, 100, 0, 30), seg(TypeStop, 5, 10, 0, 0, 0)},
+		},
+		{
+			name: "a lone zero-length segment is kept",
+			in:   []segment{seg(TypeStop, 0, 0, 0, 0, 0)},
+			want: []segment{seg(TypeStop, 0, 0, 0, 0, 0)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := merge(tt.in); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("merge = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,46 @@
+package drivestop
+
+import (
+	"time"
+
+	"github.com/alexbeattie/golangone/geo"
+	"github.com/alexbeattie/golangone/models"
+)
+
+// Settings are the device parameters that drive segmentation. Speeds are in
+// km/h to match stored points.
+type Settings struct {
+	BeginMovingSpeed  float64
+	BeginStoppedSpeed float64
+	// StopTimeout is how long a device may idle before the idle is treated as a stop.
+	StopTimeout time.Duration
+	// DriveTimeout is the longest gap between points that still continues a drive.
+	DriveTimeout time.Duration
+}
+
+// DefaultSettings mirrors the OneStepGPS defaults for a newly activated device.
+var DefaultSettings = Settings{
+	BeginMovingSpeed:  3 * geo.KmPerMile,
+	BeginStoppedSpeed: 0,
+	StopTimeout:       4 * time.Hour,
+	DriveTimeout:      30 * time.Minute,
+}
+
+// SettingsFromDevice reads segmentation settings from a device's settings,
+// falling back to DefaultSettings for anything missing.
+func SettingsFromDevice(ds models.DeviceSettings) Settings {
+	s := DefaultSettings
+	if v, ok := ds.BeginMovingSpeed.Kph(); ok {
+		s.BeginMovingSpeed = v
+	}
+	if v, ok := ds.BeginStoppedSpeed.Kph(); ok {
+		s.BeginStoppedSpeed = v
+	}
+	if v, ok := ds.StopTimeout.Duration(); ok {
+		s.StopTimeout = v
+	}
+	if v, ok := ds.DriveTimeout.Duration(); ok {
+		s.DriveTimeout = v
+	}
+	return s
+}
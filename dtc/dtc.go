@@ -0,0 +1,96 @@
+// Package dtc decodes OBD-II diagnostic trouble codes using a bundled subset
+// of the SAE J2012 generic code table.
+package dtc
+
+import (
+	_ "embed"
+	"encoding/csv"
+	"fmt"
+	"strings"
+)
+
+//go:embed j2012.csv
+var j2012CSV string
+
+// descriptions maps upper-case codes to their J2012 description.
+var descriptions = loadTable(j2012CSV)
+
+func loadTable(data string) map[string]string {
+	records, err := csv.NewReader(strings.NewReader(data)).ReadAll()
+	if err != nil {
+		panic(fmt.Sprintf("dtc: invalid bundled table: %v", err))
+	}
+
+	table := make(map[string]string, len(records))
+	for _, r := range records[1:] {
+		table[strings.ToUpper(r[0])] = r[1]
+	}
+	return table
+}
+
+// Info is a decoded trouble code.
+type Info struct {
+	Code        string `json:"code"`
+	System      string `json:"system"`
+	Generic     bool   `json:"generic"`
+	Description string `json:"description"`
+}
+
+var systems = map[byte]string{
+	'P': "Powertrain",
+	'C': "Chassis",
+	'B': "Body",
+	'U': "Network",
+}
+
+// powertrainGroups describes generic P0xxx codes by their third character
+// when the code is not in the bundled table.
+var powertrainGroups = map[byte]string{
+	'0': "Fuel and air metering and auxiliary emission controls",
+	'1': "Fuel and air metering",
+	'2': "Fuel and air metering (injector circuit)",
+	'3': "Ignition system or misfire",
+	'4': "Auxiliary emission controls",
+	'5': "Vehicle speed, idle control and auxiliary inputs",
+	'6': "Computer and output circuit",
+	'7': "Transmission",
+	'8': "Transmission",
+	'9': "Transmission",
+}
+
+// Normalize upper-cases and trims a code, e.g. " p0420" -> "P0420".
+func Normalize(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+// Decode describes code. Codes missing from the table get a description
+// derived from their structure; manufacturer-specific codes are flagged as such.
+func Decode(code string) Info {
+	code = Normalize(code)
+	info := Info{Code: code, System: "Unknown"}
+	if len(code) != 5 {
+		info.Description = "Unrecognised code"
+		return info
+	}
+
+	if system, ok := systems[code[0]]; ok {
+		info.System = system
+	}
+	// The second character is 0 (or 2, and 3 for P3400-P3999) for SAE codes.
+	info.Generic = code[1] == '0' || code[1] == '2' || (code[0] == 'P' && code[1] == '3' && code[2] >= '4')
+
+	if desc, ok := descriptions[code]; ok {
+		info.Description = desc
+		return info
+	}
+
+	switch {
+	case !info.Generic:
+		info.Description = fmt.Sprintf("Manufacturer-specific %s code", strings.ToLower(info.System))
+	case code[0] == 'P' && code[1] == '0':
+		info.Description = powertrainGroups[code[2]]
+	default:
+		info.Description = fmt.Sprintf("Generic %s code", strings.ToLower(info.System))
+	}
+	return info
+}
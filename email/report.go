@@ -0,0 +1,19 @@
+package email
+
+import "time"
+
+// ReportView is the data passed to the report template.
+type ReportView struct {
+	Name string
+	From time.Time
+	To   time.Time
+}
+
+// RenderReport renders the cover email for a scheduled report attachment.
+func RenderReport(r ReportView) (string, string, error) {
+	body, err := render("report.html", r)
+	if err != nil {
+		return "", "", err
+	}
+	return "Fleet report: " + r.Name, body, nil
+}
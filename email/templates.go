@@ -0,0 +1,61 @@
+package email
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"html/template"
+	"time"
+)
+
+//go:embed templates/*.html
+var templateFS embed.FS
+
+var templates = template.Must(template.New("").Funcs(template.FuncMap{
+	"datetime": func(t time.Time) string { return t.UTC().Format("2006-01-02 15:04 MST") },
+}).ParseFS(templateFS, "templates/*.html"))
+
+// AlertView is the data passed to alert templates.
+type AlertView struct {
+	Type        string
+	Severity    string
+	Message     string
+	DeviceID    string
+	DeviceName  string
+	TriggeredAt time.Time
+	Lat         float64
+	Lng         float64
+}
+
+// RenderAlert renders a single alert using alert_<type>.html, falling back to
+// alert_default.html for types without their own template.
+func RenderAlert(a AlertView) (string, string, error) {
+	name := "alert_" + a.Type + ".html"
+	if templates.Lookup(name) == nil {
+		name = "alert_default.html"
+	}
+
+	body, err := render(name, a)
+	if err != nil {
+		return "", "", err
+	}
+	subject := fmt.Sprintf("[%s] %s: %s", a.Severity, a.DeviceName, a.Message)
+	return subject, body, nil
+}
+
+// RenderDigest renders several alerts as one email.
+func RenderDigest(alerts []AlertView) (string, string, error) {
+	body, err := render("digest.html", alerts)
+	if err != nil {
+		return "", "", err
+	}
+	return fmt.Sprintf("%d new fleet alerts", len(alerts)), body, nil
+}
+
+func render(name string, data interface{}) (string, error) {
+	var buf bytes.Buffer
+	if err := templates.ExecuteTemplate(&buf, name, data); err != nil {
+		return "", fmt.Errorf("failed to render %s: %w", name, err)
+	}
+	return buf.String(), nil
+}
@@ -0,0 +1,76 @@
+package export
+
+import (
+	"encoding/xml"
+	"io"
+	"time"
+
+	"github.com/alexbeattie/golangone/geo"
+	"github.com/alexbeattie/golangone/models"
+)
+
+// GPXContentType is the media type for GPX documents.
+const GPXContentType = "application/gpx+xml"
+
+// WriteGPX writes t as a GPX 1.1 document: stops as waypoints followed by one
+// track. Speed and course are carried in the Garmin TrackPointExtension, with
+// speed in meters per second as that schema requires.
+func WriteGPX(out io.Writer, t Track) error {
+	w := &xmlWriter{enc: xml.NewEncoder(out)}
+	w.enc.Indent("", " ")
+
+	if _, err := io.WriteString(out, xml.Header); err != nil {
+		return err
+	}
+	w.start("gpx",
+		"version", "1.1",
+		"creator", "golangone",
+		"xmlns", "http://www.topografix.com/GPX/1/1",
+		"xmlns:gpxtpx", "http://www.garmin.com/xmlschemas/TrackPointExtension/v2",
+	)
+	w.start("metadata")
+	w.element("name", t.Name)
+	w.element("time", timestamp(time.Now()))
+	w.end("metadata")
+
+	for i, seg := range t.stops() {
+		w.start("wpt", "lat", formatFloat(seg.FirstLatLng.Lat, 6), "lon", formatFloat(seg.FirstLatLng.Lng, 6))
+		if from, err := time.Parse(time.RFC3339, seg.TimeFrom); err == nil {
+			w.element("time", timestamp(from))
+		}
+		w.element("name", stopName(seg, i+1))
+		w.element("desc", stopDescription(seg))
+		w.element("type", seg.Type)
+		w.end("wpt")
+	}
+
+	w.start("trk")
+	w.element("name", t.Name)
+	w.start("trkseg")
+
+	n := 0
+	err := t.Points(func(p models.StoredDevicePoint) error {
+		w.start("trkpt", "lat", formatFloat(p.Lat, 6), "lon", formatFloat(p.Lng, 6))
+		w.element("time", timestamp(p.DtTracker))
+		w.start("extensions")
+		w.start("gpxtpx:TrackPointExtension")
+		w.element("gpxtpx:speed", formatFloat(p.Speed/geo.KmhPerMps, 2))
+		w.element("gpxtpx:course", formatFloat(float64(p.Angle), 0))
+		w.end("gpxtpx:TrackPointExtension")
+		w.end("extensions")
+		w.end("trkpt")
+
+		if n++; n%flushEvery == 0 {
+			return w.flush()
+		}
+		return w.err
+	})
+	if err != nil {
+		return err
+	}
+
+	w.end("trkseg")
+	w.end("trk")
+	w.end("gpx")
+	return w.flush()
+}
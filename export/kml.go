@@ -0,0 +1,134 @@
+package export
+
+import (
+	"encoding/xml"
+	"io"
+	"time"
+
+	"github.com/alexbeattie/golangone/drivestop"
+	"github.com/alexbeattie/golangone/geo"
+	"github.com/alexbeattie/golangone/models"
+)
+
+// KMLContentType is the media type for KML documents.
+const KMLContentType = "application/vnd.google-earth.kml+xml"
+
+// WriteKML writes t as a KML document with the path as a timestamped
+// gx:Track carrying speed, and a folder of stop placemarks. A gx:Track lists
+// all times, then all coordinates, then all speeds, so the points are read
+// once for each list rather than buffered.
+func WriteKML(out io.Writer, t Track) error {
+	w := &xmlWriter{enc: xml.NewEncoder(out)}
+	w.enc.Indent("", " ")
+
+	speedUnit, speedScale := "km/h", 1.0
+	if t.Imperial {
+		speedUnit, speedScale = "mph", 1/geo.KmPerMile
+	}
+
+	if _, err := io.WriteString(out, xml.Header); err != nil {
+		return err
+	}
+	w.start("kml", "xmlns", "http://www.opengis.net/kml/2.2", "xmlns:gx", "http://www.google.com/kml/ext/2.2")
+	w.start("Document")
+	w.element("name", t.Name)
+	writeKMLStyle(w, drivestop.TypeStop, "http://maps.google.com/mapfiles/kml/paddle/red-square.png")
+	writeKMLStyle(w, drivestop.TypeIdle, "http://maps.google.com/mapfiles/kml/paddle/ylw-circle.png")
+	w.start("Style", "id", "track")
+	w.start("LineStyle")
+	w.element("color", "ffff7f00")
+	w.element("width", "4")
+	w.end("LineStyle")
+	w.end("Style")
+
+	w.start("Schema", "id", "trackData")
+	w.start("gx:SimpleArrayField", "name", "speed", "type", "float")
+	w.element("displayName", "Speed ("+speedUnit+")")
+	w.end("gx:SimpleArrayField")
+	w.end("Schema")
+
+	w.start("Placemark")
+	w.element("name", t.Name)
+	w.element("styleUrl", "#track")
+	w.start("gx:Track")
+
+	passes := []func(p models.StoredDevicePoint){
+		func(p models.StoredDevicePoint) { w.element("when", timestamp(p.DtTracker)) },
+		func(p models.StoredDevicePoint) {
+			w.element("gx:coord", formatFloat(p.Lng, 6)+" "+formatFloat(p.Lat, 6)+" 0")
+		},
+	}
+	for _, pass := range passes {
+		if err := writeKMLPass(w, t.Points, pass); err != nil {
+			return err
+		}
+	}
+
+	w.start("ExtendedData")
+	w.start("SchemaData", "schemaUrl", "#trackData")
+	w.start("gx:SimpleArrayData", "name", "speed")
+	err := writeKMLPass(w, t.Points, func(p models.StoredDevicePoint) {
+		w.element("gx:value", formatFloat(p.Speed*speedScale, 1))
+	})
+	if err != nil {
+		return err
+	}
+	w.end("gx:SimpleArrayData")
+	w.end("SchemaData")
+	w.end("ExtendedData")
+
+	w.end("gx:Track")
+	w.end("Placemark")
+
+	w.start("Folder")
+	w.element("name", "Stops")
+	for i, seg := range t.stops() {
+		w.start("Placemark")
+		w.element("name", stopName(seg, i+1))
+		w.element("description", stopDescription(seg))
+		from, errFrom := time.Parse(time.RFC3339, seg.TimeFrom)
+		to, errTo := time.Parse(time.RFC3339, seg.TimeTo)
+		if errFrom == nil && errTo == nil {
+			w.start("TimeSpan")
+			w.element("begin", timestamp(from))
+			w.element("end", timestamp(to))
+			w.end("TimeSpan")
+		}
+		w.element("styleUrl", "#"+seg.Type)
+		w.start("Point")
+		w.element("coordinates", formatFloat(seg.FirstLatLng.Lng, 6)+","+formatFloat(seg.FirstLatLng.Lat, 6))
+		w.end("Point")
+		w.end("Placemark")
+	}
+	w.end("Folder")
+
+	w.end("Document")
+	w.end("kml")
+	return w.flush()
+}
+
+func writeKMLStyle(w *xmlWriter, id, icon string) {
+	w.start("Style", "id", id)
+	w.start("IconStyle")
+	w.start("Icon")
+	w.element("href", icon)
+	w.end("Icon")
+	w.end("IconStyle")
+	w.end("Style")
+}
+
+// writeKMLPass reads every point once, writing each with write.
+func writeKMLPass(w *xmlWriter, points PointSource, write func(models.StoredDevicePoint)) error {
+	n := 0
+	err := points(func(p models.StoredDevicePoint) error {
+		write(p)
+		if n++; n%flushEvery == 0 {
+			return w.flush()
+		}
+		return w.err
+	})
+	if err != nil {
+		return err
+	}
+	return w.err
+}
@@ -0,0 +1,222 @@
+package export
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"time"
+
+	"github.com/jung-kurt/gofpdf"
+
+	"github.com/alexbeattie/golangone/geo"
+	"github.com/alexbeattie/golangone/reports"
+)
+
+// PDFContentType is the media type for PDF documents.
+const PDFContentType = "application/pdf"
+
+// Page layout in millimetres.
+const (
+	pdfMargin       = 15.0
+	thumbWidth      = 70.0
+	thumbHeight     = 46.0
+	thumbPadding    = 3.0
+	deviceBlockSize = thumbHeight + 10
+)
+
+// WriteFleetSummaryPDF renders a fleet summary: a table of every device with
+// fleet totals, then one block per device with its figures and a thumbnail
+// of its route drawn from the stored positions, with no map tiles or other
+// external services involved.
+func WriteFleetSummaryPDF(out io.Writer, summary *reports.FleetSummary, loc *time.Location) error {
+	pdf := gofpdf.New("P", "mm", "Letter", "")
+	pdf.SetMargins(pdfMargin, pdfMargin, pdfMargin)
+	pdf.SetAutoPageBreak(true, pdfMargin)
+	pdf.AliasNbPages("")
+	tr := pdf.UnicodeTranslatorFromDescriptor("")
+
+	distUnit, speedUnit := "km", "km/h"
+	if summary.Imperial {
+		distUnit, speedUnit = "mi", "mph"
+	}
+
+	generated := time.Now().In(loc).Format("2006-01-02 15:04 MST")
+	pdf.SetFooterFunc(func() {
+		pdf.SetY(-pdfMargin + 5)
+		pdf.SetFont("Helvetica", "", 8)
+		pdf.SetTextColor(128, 128, 128)
+		pdf.CellFormat(0, 5, "Generated "+generated, "", 0, "L", false, 0, "")
+		pdf.CellFormat(0, 5, fmt.Sprintf("Page %d/{nb}", pdf.PageNo()), "", 0, "R", false, 0, "")
+	})
+
+	pdf.AddPage()
+	pdf.SetFont("Helvetica", "B", 18)
+	pdf.CellFormat(0, 10, "Fleet Summary", "", 1, "L", false, 0, "")
+	pdf.SetFont("Helvetica", "", 10)
+	pdf.CellFormat(0, 6, fmt.Sprintf("%s to %s",
+		summary.From.In(loc).Format("2006-01-02 15:04"), summary.To.In(loc).Format("2006-01-02 15:04 MST")),
+		"", 1, "L", false, 0, "")
+	pdf.Ln(4)
+
+	// Overview table.
+	cols := []struct {
+		title string
+		width float64
+		align string
+	}{
+		{"Device", 62, "L"},
+		{"Distance (" + distUnit + ")", 26, "R"},
+		{"Drive time", 24, "R"},
+		{"Idle time", 24, "R"},
+		{"Stops", 16, "R"},
+		{"Top speed (" + speedUnit + ")", 34, "R"},
+	}
+	row := func(values []string, bold, fill bool) {
+		style := ""
+		if bold {
+			style = "B"
+		}
+		pdf.SetFont("Helvetica", style, 9)
+		for i, c := range cols {
+			pdf.CellFormat(c.width, 7, tr(values[i]), "B", 0, c.align, fill, 0, "")
+		}
+		pdf.Ln(-1)
+	}
+	figures := func(name string, d reports.DeviceSummary) []string {
+		return []string{
+			name,
+			fmt.Sprintf("%.1f", d.Distance),
+			formatHours(d.DriveTime),
+			formatHours(d.IdleTime),
+			fmt.Sprintf("%d", d.StopCount),
+			fmt.Sprintf("%.0f", d.TopSpeed),
+		}
+	}
+
+	pdf.SetFillColor(235, 238, 242)
+	titles := make([]string, len(cols))
+	for i, c := range cols {
+		titles[i] = c.title
+	}
+	row(titles, true, true)
+	for _, d := range summary.Devices {
+		row(figures(d.Name, d), false, false)
+	}
+	row(figures(fmt.Sprintf("Fleet (%d devices)", len(summary.Devices)), summary.Totals()), true, true)
+
+	// Per-device blocks.
+	pdf.Ln(8)
+	_, pageHeight := pdf.GetPageSize()
+	for _, d := range summary.Devices {
+		if pdf.GetY()+deviceBlockSize > pageHeight-pdfMargin {
+			pdf.AddPage()
+		}
+		y := pdf.GetY()
+
+		drawRouteThumbnail(pdf, d.Path, pdfMargin, y, thumbWidth, thumbHeight)
+
+		x := pdfMargin + thumbWidth + 8
+		pdf.SetXY(x, y)
+		pdf.SetFont("Helvetica", "B", 12)
+		pdf.CellFormat(0, 7, tr(d.Name), "", 2, "L", false, 0, "")
+		pdf.SetFont("Helvetica", "", 8)
+		pdf.SetTextColor(110, 110, 110)
+		pdf.CellFormat(0, 5, d.DeviceID, "", 2, "L", false, 0, "")
+		pdf.SetTextColor(0, 0, 0)
+		pdf.Ln(2)
+
+		stats := [][2]string{
+			{"Distance", fmt.Sprintf("%.1f %s", d.Distance, distUnit)},
+			{"Drive time", formatHours(d.DriveTime)},
+			{"Idle time", formatHours(d.IdleTime)},
+			{"Stops", fmt.Sprintf("%d", d.StopCount)},
+			{"Top speed", fmt.Sprintf("%.0f %s", d.TopSpeed, speedUnit)},
+		}
+		for _, s := range stats {
+			pdf.SetX(x)
+			pdf.SetFont("Helvetica", "", 10)
+			pdf.CellFormat(30, 6, s[0], "", 0, "L", false, 0, "")
+			pdf.SetFont("Helvetica", "B", 10)
+			pdf.CellFormat(40, 6, s[1], "", 1, "L", false, 0, "")
+		}
+
+		pdf.SetY(y + deviceBlockSize)
+	}
+
+	return pdf.Output(out)
+}
+
+// drawRouteThumbnail draws path inside the box at x, y as a polyline on a
+// plain background, marking the start in green and the end in red. Positions
+// are projected equirectangularly around the path's mid latitude, which is
+// accurate enough at the scale of a day's driving.
+func drawRouteThumbnail(pdf *gofpdf.Fpdf, path []geo.Point, x, y, w, h float64) {
+	pdf.SetDrawColor(200, 205, 212)
+	pdf.SetFillColor(246, 247, 249)
+	pdf.SetLineWidth(0.2)
+	pdf.Rect(x, y, w, h, "FD")
+
+	if len(path) == 0 {
+		pdf.SetXY(x, y+h/2-3)
+		pdf.SetFont("Helvetica", "I", 8)
+		pdf.SetTextColor(140, 140, 140)
+		pdf.CellFormat(w, 6, "No positions in this period", "", 0, "C", false, 0, "")
+		pdf.SetTextColor(0, 0, 0)
+		return
+	}
+
+	minLat, maxLat := path[0].Lat, path[0].Lat
+	minLng, maxLng := path[0].Lng, path[0].Lng
+	for _, p := range path[1:] {
+		minLat, maxLat = math.Min(minLat, p.Lat), math.Max(maxLat, p.Lat)
+		minLng, maxLng = math.Min(minLng, p.Lng), math.Max(maxLng, p.Lng)
+	}
+	midLat, midLng := (minLat+maxLat)/2, (minLng+maxLng)/2
+	kx := math.Cos(midLat * math.Pi / 180)
+
+	spanX, spanY := (maxLng-minLng)*kx, maxLat-minLat
+	scale := math.Inf(1)
+	if spanX > 0 {
+		scale = (w - 2*thumbPadding) / spanX
+	}
+	if spanY > 0 {
+		scale = math.Min(scale, (h-2*thumbPadding)/spanY)
+	}
+	if math.IsInf(scale, 1) {
+		scale = 0 // a single location: draw it at the centre
+	}
+
+	project := func(p geo.Point) (float64, float64) {
+		return x + w/2 + (p.Lng-midLng)*kx*scale, y + h/2 - (p.Lat-midLat)*scale
+	}
+
+	pdf.SetDrawColor(30, 110, 220)
+	pdf.SetLineWidth(0.6)
+	pdf.SetLineCapStyle("round")
+	pdf.SetLineJoinStyle("round")
+	px, py := project(path[0])
+	pdf.MoveTo(px, py)
+	for _, p := range path[1:] {
+		pdf.LineTo(project(p))
+	}
+	pdf.DrawPath("D")
+
+	pdf.SetLineWidth(0.2)
+	pdf.SetDrawColor(255, 255, 255)
+	pdf.SetFillColor(40, 170, 80)
+	pdf.Circle(px, py, 1.2, "FD")
+	ex, ey := project(path[len(path)-1])
+	pdf.SetFillColor(210, 50, 50)
+	pdf.Circle(ex, ey, 1.2, "FD")
+}
+
+// formatHours renders d as "12h 05m".
+func formatHours(d time.Duration) string {
+	d = d.Round(time.Minute)
+	return fmt.Sprintf("%dh %02dm", int(d.Hours()), int(d.Minutes())%60)
+}
+
+// SummaryFilename returns the download name for a fleet summary PDF.
+func SummaryFilename(deviceID string, from, to time.Time) string {
+	return reportFilename(deviceID, "summary", from, to, "pdf")
+}
@@ -0,0 +1,102 @@
+// Package export writes device history in formats other tools can open.
+package export
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/alexbeattie/golangone/drivestop"
+	"github.com/alexbeattie/golangone/models"
+)
+
+// PointSource streams points in time order to fn, stopping at its first error.
+// WriteKML calls it more than once, so it must yield the same points each time.
+type PointSource func(fn func(models.StoredDevicePoint) error) error
+
+// Track is a device's path over a time window.
+type Track struct {
+	Name string
+	// Segments from the drive-stop engine; stops and idles become waypoints.
+	Segments []models.DriveStopPoint
+	Points   PointSource
+	// Imperial reports speeds in mph instead of km/h where the format allows a choice.
+	Imperial bool
+}
+
+// flushEvery is how many points are encoded between flushes to the client.
+const flushEvery = 500
+
+// stops returns the non-drive segments.
+func (t Track) stops() []models.DriveStopPoint {
+	var out []models.DriveStopPoint
+	for _, seg := range t.Segments {
+		if seg.Type != drivestop.TypeDrive {
+			out = append(out, seg)
+		}
+	}
+	return out
+}
+
+func stopName(seg models.DriveStopPoint, n int) string {
+	if seg.Type == drivestop.TypeIdle {
+		return fmt.Sprintf("Idle %d", n)
+	}
+	return fmt.Sprintf("Stop %d", n)
+}
+
+func stopDescription(seg models.DriveStopPoint) string {
+	return fmt.Sprintf("%s for %s, %s to %s", seg.Type, seg.Duration.Display, seg.TimeFrom, seg.TimeTo)
+}
+
+func formatFloat(v float64, prec int) string {
+	return strconv.FormatFloat(v, 'f', prec, 64)
+}
+
+// xmlWriter emits tokens, remembering the first error so callers can check once.
+type xmlWriter struct {
+	enc *xml.Encoder
+	err error
+}
+
+func (w *xmlWriter) token(t xml.Token) {
+	if w.err == nil {
+		w.err = w.enc.EncodeToken(t)
+	}
+}
+
+// Names are written verbatim, so prefixed names like "gx:coord" are used as-is.
+func (w *xmlWriter) start(name string, attrs ...string) {
+	el := xml.StartElement{Name: xml.Name{Local: name}}
+	for i := 0; i+1 < len(attrs); i += 2 {
+		el.Attr = append(el.Attr, xml.Attr{Name: xml.Name{Local: attrs[i]}, Value: attrs[i+1]})
+	}
+	w.token(el)
+}
+
+func (w *xmlWriter) end(name string) {
+	w.token(xml.EndElement{Name: xml.Name{Local: name}})
+}
+
+func (w *xmlWriter) text(s string) {
+	w.token(xml.CharData(s))
+}
+
+// element writes <name>value</name>.
+func (w *xmlWriter) element(name, value string) {
+	w.start(name)
+	w.text(value)
+	w.end(name)
+}
+
+func (w *xmlWriter) flush() error {
+	if w.err == nil {
+		w.err = w.enc.Flush()
+	}
+	return w.err
+}
+
+func timestamp(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
@@ -0,0 +1,183 @@
+package export
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/xuri/excelize/v2"
+
+	"github.com/alexbeattie/golangone/drivestop"
+	"github.com/alexbeattie/golangone/reports"
+)
+
+// Media types for tabular exports.
+const (
+	CSVContentType  = "text/csv; charset=utf-8"
+	XLSXContentType = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+)
+
+// TableOptions control how trip rows are rendered.
+type TableOptions struct {
+	Imperial bool
+	// Location is the timezone timestamps are shown in.
+	Location *time.Location
+}
+
+func (o TableOptions) header() []string {
+	unit := "km"
+	if o.Imperial {
+		unit = "mi"
+	}
+	tz := o.Location.String()
+	return []string{
+		"Device", "Device ID", "Type",
+		"Start (" + tz + ")", "End (" + tz + ")", "Duration",
+		"Distance (" + unit + ")",
+		"Start Lat", "Start Lng", "End Lat", "End Lng",
+		"Odometer From (" + unit + ")", "Odometer To (" + unit + ")",
+	}
+}
+
+const tableTimeLayout = "2006-01-02 15:04:05"
+
+// formatClock renders d as h:mm:ss.
+func formatClock(d time.Duration) string {
+	d = d.Round(time.Second)
+	return fmt.Sprintf("%d:%02d:%02d", int(d.Hours()), int(d.Minutes())%60, int(d.Seconds())%60)
+}
+
+// WriteTripsCSV writes rows as CSV with a header line.
+func WriteTripsCSV(out io.Writer, rows []reports.TripRow, opts TableOptions) error {
+	w := csv.NewWriter(out)
+	if err := w.Write(opts.header()); err != nil {
+		return err
+	}
+
+	for _, r := range rows {
+		distance := ""
+		if r.Type == drivestop.TypeDrive {
+			distance = formatFloat(r.Distance, 2)
+		}
+		record := []string{
+			r.Name, r.DeviceID, r.Type,
+			r.Start.In(opts.Location).Format(tableTimeLayout),
+			r.End.In(opts.Location).Format(tableTimeLayout),
+			formatClock(r.Duration),
+			distance,
+			formatFloat(r.StartLat, 6), formatFloat(r.StartLng, 6),
+			formatFloat(r.EndLat, 6), formatFloat(r.EndLng, 6),
+			formatFloat(r.OdometerFrom, 1), formatFloat(r.OdometerTo, 1),
+		}
+		if err := w.Write(record); err != nil {
+			return err
+		}
+	}
+	w.Flush()
+	return w.Error()
+}
+
+// WriteTripsXLSX writes rows as a single-sheet workbook with native date,
+// duration and number cells so the sheet can be sorted and summed.
+func WriteTripsXLSX(out io.Writer, rows []reports.TripRow, opts TableOptions) error {
+	f := excelize.NewFile()
+	defer f.Close()
+
+	const sheet = "Trips"
+	if err := f.SetSheetName("Sheet1", sheet); err != nil {
+		return err
+	}
+
+	bold, err := f.NewStyle(&excelize.Style{Font: &excelize.Font{Bold: true}})
+	if err != nil {
+		return err
+	}
+	dateFmt, durationFmt, numberFmt := "yyyy-mm-dd hh:mm:ss", "[h]:mm:ss", "0.00"
+	dateStyle, err := f.NewStyle(&excelize.Style{CustomNumFmt: &dateFmt})
+	if err != nil {
+		return err
+	}
+	durationStyle, err := f.NewStyle(&excelize.Style{CustomNumFmt: &durationFmt})
+	if err != nil {
+		return err
+	}
+	numberStyle, err := f.NewStyle(&excelize.Style{CustomNumFmt: &numberFmt})
+	if err != nil {
+		return err
+	}
+
+	sw, err := f.NewStreamWriter(sheet)
+	if err != nil {
+		return err
+	}
+	if err := sw.SetColWidth(1, 2, 24); err != nil {
+		return err
+	}
+	if err := sw.SetColWidth(4, 5, 20); err != nil {
+		return err
+	}
+	if err := sw.SetPanes(&excelize.Panes{Freeze: true, YSplit: 1, TopLeftCell: "A2", ActivePane: "bottomLeft"}); err != nil {
+		return err
+	}
+
+	header := opts.header()
+	cells := make([]interface{}, len(header))
+	for i, h := range header {
+		cells[i] = excelize.Cell{StyleID: bold, Value: h}
+	}
+	if err := sw.SetRow("A1", cells); err != nil {
+		return err
+	}
+
+	for i, r := range rows {
+		var distance interface{}
+		if r.Type == drivestop.TypeDrive {
+			distance = excelize.Cell{StyleID: numberStyle, Value: r.Distance}
+		}
+		cells := []interface{}{
+			r.Name, r.DeviceID, r.Type,
+			excelize.Cell{StyleID: dateStyle, Value: wallClock(r.Start, opts.Location)},
+			excelize.Cell{StyleID: dateStyle, Value: wallClock(r.End, opts.Location)},
+			// Excel durations are fractions of a day.
+			excelize.Cell{StyleID: durationStyle, Value: r.Duration.Hours() / 24},
+			distance,
+			r.StartLat, r.StartLng, r.EndLat, r.EndLng,
+			excelize.Cell{StyleID: numberStyle, Value: r.OdometerFrom},
+			excelize.Cell{StyleID: numberStyle, Value: r.OdometerTo},
+		}
+		cell, err := excelize.CoordinatesToCellName(1, i+2)
+		if err != nil {
+			return err
+		}
+		if err := sw.SetRow(cell, cells); err != nil {
+			return err
+		}
+	}
+
+	if err := sw.Flush(); err != nil {
+		return err
+	}
+	return f.Write(out)
+}
+
+// wallClock returns t as shown in loc, relabelled as UTC. Excel dates carry
+// no zone and excelize converts from UTC, so this keeps the local reading.
+func wallClock(t time.Time, loc *time.Location) time.Time {
+	l := t.In(loc)
+	return time.Date(l.Year(), l.Month(), l.Day(), l.Hour(), l.Minute(), l.Second(), 0, time.UTC)
+}
+
+// TripsFilename returns the download name for a trip export.
+func TripsFilename(deviceID string, from, to time.Time, ext string) string {
+	return reportFilename(deviceID, "trips", from, to, ext)
+}
+
+// reportFilename names a report download after its subject, kind and dates.
+func reportFilename(deviceID, kind string, from, to time.Time, ext string) string {
+	name := "fleet"
+	if deviceID != "" {
+		name = deviceID
+	}
+	return name + "-" + kind + "-" + from.Format("20060102") + "-" + to.Format("20060102") + "." + ext
+}
@@ -0,0 +1,32 @@
+// Package geo holds the small amount of spherical geometry the server needs.
+package geo
+
+import "math"
+
+// EarthRadiusMeters is the mean Earth radius used for distance calculations.
+const EarthRadiusMeters = 6371008.8
+
+// KmPerMile is the length of an international mile in kilometres, and so
+// also the number of km/h in one mph.
+const KmPerMile = 1.609344
+
+// LitresPerGal is the volume of a US gallon in litres.
+const LitresPerGal = 3.785411784
+
+// KmhPerMps is the number of km/h in one m/s.
+const KmhPerMps = 3.6
+
+func toRadians(deg float64) float64 {
+	return deg * math.Pi / 180
+}
+
+// DistanceMeters returns the great-circle (haversine) distance between two
+// coordinates given in decimal degrees.
+func DistanceMeters(lat1, lng1, lat2, lng2 float64) float64 {
+	dLat := toRadians(lat2 - lat1)
+	dLng := toRadians(lng2 - lng1)
+
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(toRadians(lat1))*math.Cos(toRadians(lat2))*math.Sin(dLng/2)*math.Sin(dLng/2)
+	return 2 * EarthRadiusMeters * math.Asin(math.Min(1, math.Sqrt(a)))
+}
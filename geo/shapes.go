@@ -0,0 +1,211 @@
+package geo
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+// Point is a coordinate in decimal degrees.
+type Point struct {
+	Lat float64
+	Lng float64
+}
+
+// Shape is an area that can be tested for containment.
+type Shape interface {
+	Contains(p Point) bool
+	// BoundaryDistance returns the distance in meters from p to the shape's
+	// edge: negative inside, positive outside.
+	BoundaryDistance(p Point) float64
+}
+
+// Circle is the set of points within Radius meters of Center.
+type Circle struct {
+	Center Point
+	Radius float64
+}
+
+func (c Circle) Contains(p Point) bool {
+	return DistanceMeters(c.Center.Lat, c.Center.Lng, p.Lat, p.Lng) <= c.Radius
+}
+
+func (c Circle) BoundaryDistance(p Point) float64 {
+	return DistanceMeters(c.Center.Lat, c.Center.Lng, p.Lat, p.Lng) - c.Radius
+}
+
+// Polygon is an outer ring followed by optional holes. Rings are closed: the
+// first and last points are equal.
+type Polygon struct {
+	Rings [][]Point
+}
+
+// Contains reports whether p lies inside the outer ring and outside every hole.
+func (pg Polygon) Contains(p Point) bool {
+	if len(pg.Rings) == 0 || !ringContains(pg.Rings[0], p) {
+		return false
+	}
+	for _, hole := range pg.Rings[1:] {
+		if ringContains(hole, p) {
+			return false
+		}
+	}
+	return true
+}
+
+func (pg Polygon) BoundaryDistance(p Point) float64 {
+	nearest := math.Inf(1)
+	for _, ring := range pg.Rings {
+		for i := 0; i+1 < len(ring); i++ {
+			nearest = math.Min(nearest, edgeDistance(p, ring[i], ring[i+1]))
+		}
+	}
+	if pg.Contains(p) {
+		return -nearest
+	}
+	return nearest
+}
+
+// edgeDistance returns the distance in meters from p to the segment a-b,
+// using an equirectangular projection centred on p. That is accurate enough
+// at geofence scale.
+func edgeDistance(p, a, b Point) float64 {
+	kx := toRadians(1) * EarthRadiusMeters * math.Cos(toRadians(p.Lat))
+	ky := toRadians(1) * EarthRadiusMeters
+
+	ax, ay := (a.Lng-p.Lng)*kx, (a.Lat-p.Lat)*ky
+	bx, by := (b.Lng-p.Lng)*kx, (b.Lat-p.Lat)*ky
+	dx, dy := bx-ax, by-ay
+
+	t := 0.0
+	if l := dx*dx + dy*dy; l > 0 {
+		t = math.Max(0, math.Min(1, -(ax*dx+ay*dy)/l))
+	}
+	return math.Hypot(ax+t*dx, ay+t*dy)
+}
+
+// ringContains is the even-odd ray casting test on a closed ring.
+func ringContains(ring []Point, p Point) bool {
+	inside := false
+	for i, j := 0, len(ring)-1; i < len(ring); j, i = i, i+1 {
+		a, b := ring[i], ring[j]
+		if (a.Lat > p.Lat) != (b.Lat > p.Lat) &&
+			p.Lng < (b.Lng-a.Lng)*(p.Lat-a.Lat)/(b.Lat-a.Lat)+a.Lng {
+			inside = !inside
+		}
+	}
+	return inside
+}
+
+// ValidatePoint checks that p is a valid WGS84 coordinate.
+func ValidatePoint(p Point) error {
+	if math.IsNaN(p.Lat) || math.IsNaN(p.Lng) || p.Lat < -90 || p.Lat > 90 || p.Lng < -180 || p.Lng > 180 {
+		return fmt.Errorf("coordinate (%g, %g) is out of range", p.Lat, p.Lng)
+	}
+	return nil
+}
+
+// Validate checks that every ring is closed, has at least three distinct
+// vertices, has valid coordinates and does not intersect itself, and that
+// every hole lies inside the outer ring.
+func (pg Polygon) Validate() error {
+	if len(pg.Rings) == 0 {
+		return errors.New("polygon has no rings")
+	}
+	for i, ring := range pg.Rings {
+		if len(ring) < 4 {
+			return fmt.Errorf("ring %d needs at least 4 positions", i)
+		}
+		if ring[0] != ring[len(ring)-1] {
+			return fmt.Errorf("ring %d is not closed", i)
+		}
+		for _, p := range ring {
+			if err := ValidatePoint(p); err != nil {
+				return fmt.Errorf("ring %d: %w", i, err)
+			}
+		}
+		if distinctVertices(ring) < 3 {
+			return fmt.Errorf("ring %d needs at least 3 distinct vertices", i)
+		}
+		if selfIntersects(ring) {
+			return fmt.Errorf("ring %d intersects itself", i)
+		}
+	}
+
+	outer := pg.Rings[0]
+	for i, hole := range pg.Rings[1:] {
+		if !ringWithin(hole, outer) {
+			return fmt.Errorf("ring %d is not inside the outer ring", i+1)
+		}
+	}
+	return nil
+}
+
+// distinctVertices counts the distinct vertices of a closed ring.
+func distinctVertices(ring []Point) int {
+	seen := make(map[Point]struct{}, len(ring))
+	for _, p := range ring[:len(ring)-1] {
+		seen[p] = struct{}{}
+	}
+	return len(seen)
+}
+
+// ringWithin reports whether the closed ring inner lies inside outer: none of
+// their edges meet and inner has a vertex inside outer.
+func ringWithin(inner, outer []Point) bool {
+	for i := 0; i < len(inner)-1; i++ {
+		for j := 0; j < len(outer)-1; j++ {
+			if segmentsIntersect(inner[i], inner[i+1], outer[j], outer[j+1]) {
+				return false
+			}
+		}
+	}
+	return ringContains(outer, inner[0])
+}
+
+// selfIntersects reports whether any two non-adjacent edges of a closed ring cross or touch.
+func selfIntersects(ring []Point) bool {
+	n := len(ring) - 1 // number of edges
+	for i := 0; i < n; i++ {
+		for j := i + 1; j < n; j++ {
+			if j == i+1 || (i == 0 && j == n-1) {
+				continue // adjacent edges share a vertex
+			}
+			if segmentsIntersect(ring[i], ring[i+1], ring[j], ring[j+1]) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// orientation returns the sign of the cross product (b-a) x (c-a).
+func orientation(a, b, c Point) int {
+	v := (b.Lng-a.Lng)*(c.Lat-a.Lat) - (b.Lat-a.Lat)*(c.Lng-a.Lng)
+	switch {
+	case v > 0:
+		return 1
+	case v < 0:
+		return -1
+	default:
+		return 0
+	}
+}
+
+func onSegment(a, b, p Point) bool {
+	return math.Min(a.Lng, b.Lng) <= p.Lng && p.Lng <= math.Max(a.Lng, b.Lng) &&
+		math.Min(a.Lat, b.Lat) <= p.Lat && p.Lat <= math.Max(a.Lat, b.Lat)
+}
+
+func segmentsIntersect(p1, p2, q1, q2 Point) bool {
+	o1, o2 := orientation(p1, p2, q1), orientation(p1, p2, q2)
+	o3, o4 := orientation(q1, q2, p1), orientation(q1, q2, p2)
+
+	if o1 != o2 && o3 != o4 {
+		return true
+	}
+	return (o1 == 0 && onSegment(p1, p2, q1)) ||
+		(o2 == 0 && onSegment(p1, p2, q2)) ||
+		(o3 == 0 && onSegment(q1, q2, p1)) ||
+		(o4 == 0 && onSegment(q1, q2, p2))
+}
@@ -0,0 +1,91 @@
+package geo
+
+import (
+	"strings"
+	"testing"
+)
+
+// ring builds a closed ring from lng, lat pairs, as GeoJSON orders them.
+func ring(coords ...[2]float64) []Point {
+	r := make([]Point, 0, len(coords)+1)
+	for _, c := range coords {
+		r = append(r, Point{Lat: c[1], Lng: c[0]})
+	}
+	return append(r, r[0])
+}
+
+func TestPolygonValidate(t *testing.T) {
+	square := ring([2]float64{0, 0}, [2]float64{10, 0}, [2]float64{10, 10}, [2]float64{0, 10})
+
+	tests := []struct {
+		name    string
+		rings   [][]Point
+		wantErr string
+	}{
+		{name: "square", rings: [][]Point{square}},
+		{
+			name:  "square with a hole",
+			rings: [][]Point{square, ring([2]float64{2, 2}, [2]float64{4, 2}, [2]float64{4, 4}, [2]float64{2, 4})},
+		},
+		{
+			name:  "concave ring",
+			rings: [][]Point{ring([2]float64{0, 0}, [2]float64{10, 0}, [2]float64{5, 5}, [2]float64{10, 10}, [2]float64{0, 10})},
+		},
+		{
+			name:    "bow tie crosses itself",
+			rings:   [][]Point{ring([2]float64{0, 0}, [2]float64{10, 10}, [2]float64{10, 0}, [2]float64{0, 10})},
+			wantErr: "ring 0 intersects itself",
+		},
+		{
+			name:    "vertex touching a non-adjacent edge",
+			rings:   [][]Point{ring([2]float64{0, 0}, [2]float64{10, 0}, [2]float64{10, 10}, [2]float64{5, 0}, [2]float64{0, 10})},
+			wantErr: "ring 0 intersects itself",
+		},
+		{
+			name:    "self-intersecting hole",
+			rings:   [][]Point{square, ring([2]float64{2, 2}, [2]float64{4, 4}, [2]float64{4, 2}, [2]float64{2, 4})},
+			wantErr: "ring 1 intersects itself",
+		},
+		{
+			name:    "hole outside the outer ring",
+			rings:   [][]Point{square, ring([2]float64{20, 20}, [2]float64{22, 20}, [2]float64{22, 22}, [2]float64{20, 22})},
+			wantErr: "ring 1 is not inside the outer ring",
+		},
+		{
+			name:    "hole crossing the outer ring",
+			rings:   [][]Point{square, ring([2]float64{8, 2}, [2]float64{12, 2}, [2]float64{12, 4}, [2]float64{8, 4})},
+			wantErr: "ring 1 is not inside the outer ring",
+		},
+		{
+			name:    "hole enclosing the outer ring",
+			rings:   [][]Point{square, ring([2]float64{-5, -5}, [2]float64{15, -5}, [2]float64{15, 15}, [2]float64{-5, 15})},
+			wantErr: "ring 1 is not inside the outer ring",
+		},
+		{
+			name:    "open ring",
+			rings:   [][]Point{square[:len(square)-1]},
+			wantErr: "ring 0 is not closed",
+		},
+		{
+			name:    "too few distinct vertices",
+			rings:   [][]Point{ring([2]float64{0, 0}, [2]float64{10, 0}, [2]float64{10, 0})},
+			wantErr: "ring 0 needs at least 3 distinct vertices",
+		},
+		{name: "no rings", wantErr: "polygon has no rings"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Polygon{Rings: tt.rings}.Validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("err = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
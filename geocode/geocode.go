@@ -0,0 +1,46 @@
+// Package geocode resolves coordinates to human-readable addresses.
+package geocode
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ErrNoResult is returned when a provider has no address for a location.
+var ErrNoResult = errors.New("no address found")
+
+// Address is a reverse geocoded location.
+type Address struct {
+	Formatted   string `json:"formatted"`
+	City        string `json:"city,omitempty"`
+	State       string `json:"state,omitempty"`
+	Country     string `json:"country,omitempty"`
+	CountryCode string `json:"country_code,omitempty"`
+}
+
+// Provider resolves a coordinate to an address.
+type Provider interface {
+	Name() string
+	Reverse(ctx context.Context, lat, lng float64) (*Address, error)
+}
+
+// LocalProvider is implemented by providers that answer from memory without
+// calling out. Their lookups are cheap, so they need no rate limiting or
+// caching.
+type LocalProvider interface {
+	Provider
+	Local() bool
+}
+
+// StubProvider formats the coordinate itself as the address. It never calls
+// out and is meant for tests and local development.
+type StubProvider struct{}
+
+func (StubProvider) Name() string { return "stub" }
+
+func (StubProvider) Local() bool { return true }
+
+func (StubProvider) Reverse(ctx context.Context, lat, lng float64) (*Address, error) {
+	return &Address{Formatted: fmt.Sprintf("%.5f, %.5f", lat, lng)}, nil
+}
@@ -0,0 +1,114 @@
+package geocode
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// DefaultGoogleBaseURL is the Google Maps Geocoding API endpoint.
+const DefaultGoogleBaseURL = "https://maps.googleapis.com/maps/api/geocode/json"
+
+// GoogleProvider reverse geocodes with the Google Maps Geocoding API.
+type GoogleProvider struct {
+	APIKey  string
+	BaseURL string
+	Client  *http.Client
+}
+
+func NewGoogleProvider(apiKey string) *GoogleProvider {
+	return &GoogleProvider{
+		APIKey:  apiKey,
+		BaseURL: DefaultGoogleBaseURL,
+		Client:  &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+func (g *GoogleProvider) Name() string { return "google" }
+
+type googleResponse struct {
+	Status       string `json:"status"`
+	ErrorMessage string `json:"error_message"`
+	Results      []struct {
+		FormattedAddress  string `json:"formatted_address"`
+		AddressComponents []struct {
+			LongName  string   `json:"long_name"`
+			ShortName string   `json:"short_name"`
+			Types     []string `json:"types"`
+		} `json:"address_components"`
+	} `json:"results"`
+}
+
+func (g *GoogleProvider) Reverse(ctx context.Context, lat, lng float64) (*Address, error) {
+	q := url.Values{}
+	q.Set("latlng", strconv.FormatFloat(lat, 'f', -1, 64)+","+strconv.FormatFloat(lng, 'f', -1, 64))
+	q.Set("key", g.APIKey)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, g.BaseURL+"?"+q.Encode(), nil)
+	if err != nil {
+		return nil, redactURL(err)
+	}
+	resp, err := g.Client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("google geocode request failed: %w", redactURL(err))
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("google geocode returned HTTP %d", resp.StatusCode)
+	}
+
+	var body googleResponse
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("failed to decode google geocode response: %w", err)
+	}
+
+	switch body.Status {
+	case "OK":
+	case "ZERO_RESULTS":
+		return nil, ErrNoResult
+	default:
+		return nil, fmt.Errorf("google geocode status %s: %s", body.Status, body.ErrorMessage)
+	}
+	if len(body.Results) == 0 {
+		return nil, ErrNoResult
+	}
+
+	result := body.Results[0]
+	addr := &Address{Formatted: result.FormattedAddress}
+	for _, c := range result.AddressComponents {
+		for _, t := range c.Types {
+			switch t {
+			case "locality":
+				addr.City = c.LongName
+			case "administrative_area_level_1":
+				addr.State = c.ShortName
+			case "country":
+				addr.Country, addr.CountryCode = c.LongName, c.ShortName
+			}
+		}
+	}
+	return addr, nil
+}
+
+// redactURL strips the query, which carries the API key, from the URL in a
+// transport error so callers can log it.
+func redactURL(err error) error {
+	var urlErr *url.Error
+	if !errors.As(err, &urlErr) {
+		return err
+	}
+	redacted := *urlErr
+	if u, parseErr := url.Parse(urlErr.URL); parseErr == nil {
+		u.RawQuery = ""
+		redacted.URL = u.String()
+	} else {
+		redacted.URL = "<redacted>"
+	}
+	return &redacted
+}
@@ -0,0 +1,87 @@
+package geocode
+
+import (
+	"math"
+	"sort"
+)
+
+// kdTree indexes places by their position on the unit sphere, so Euclidean
+// (chord) distance orders neighbors the same way great-circle distance does
+// and there is no seam at the antimeridian.
+type kdTree struct {
+	root *kdNode
+}
+
+type kdNode struct {
+	place       *Place
+	pos         [3]float64
+	axis        int
+	left, right *kdNode
+}
+
+func unitVector(lat, lng float64) [3]float64 {
+	phi, lambda := lat*math.Pi/180, lng*math.Pi/180
+	return [3]float64{math.Cos(phi) * math.Cos(lambda), math.Cos(phi) * math.Sin(lambda), math.Sin(phi)}
+}
+
+func newKDTree(places []Place) *kdTree {
+	nodes := make([]*kdNode, len(places))
+	for i := range places {
+		nodes[i] = &kdNode{place: &places[i], pos: unitVector(places[i].Lat, places[i].Lng)}
+	}
+	return &kdTree{root: buildKD(nodes, 0)}
+}
+
+func buildKD(nodes []*kdNode, depth int) *kdNode {
+	if len(nodes) == 0 {
+		return nil
+	}
+	axis := depth % 3
+	sort.Slice(nodes, func(i, j int) bool { return nodes[i].pos[axis] < nodes[j].pos[axis] })
+
+	mid := len(nodes) / 2
+	n := nodes[mid]
+	n.axis = axis
+	n.left = buildKD(nodes[:mid], depth+1)
+	n.right = buildKD(nodes[mid+1:], depth+1)
+	return n
+}
+
+// nearest returns the place closest to lat/lng and the squared chord
+// distance to it on the unit sphere.
+func (t *kdTree) nearest(lat, lng float64) (*Place, float64) {
+	target := unitVector(lat, lng)
+	var best *kdNode
+	bestDist := math.Inf(1)
+
+	var search func(n *kdNode)
+	search = func(n *kdNode) {
+		if n == nil {
+			return
+		}
+		if d := sqDist(n.pos, target); d < bestDist {
+			best, bestDist = n, d
+		}
+
+		diff := target[n.axis] - n.pos[n.axis]
+		near, far := n.left, n.right
+		if diff > 0 {
+			near, far = n.right, n.left
+		}
+		search(near)
+		if diff*diff < bestDist {
+			search(far)
+		}
+	}
+	search(t.root)
+
+	if best == nil {
+		return nil, 0
+	}
+	return best.place, bestDist
+}
+
+func sqDist(a, b [3]float64) float64 {
+	dx, dy, dz := a[0]-b[0], a[1]-b[1], a[2]-b[2]
+	return dx*dx + dy*dy + dz*dz
+}
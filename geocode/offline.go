@@ -0,0 +1,164 @@
+package geocode
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	_ "embed"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/alexbeattie/golangone/geo"
+)
+
+// citiesData is a small sample of major cities in GeoNames cities*.txt
+// format. Deployments wanting street-level coverage should load a full
+// GeoNames extract such as cities1000.txt instead.
+//
+//go:embed cities.txt
+var citiesData []byte
+
+// Place is a populated place from the offline dataset.
+type Place struct {
+	Name        string
+	Lat         float64
+	Lng         float64
+	CountryCode string
+	Admin1      string
+	Population  int
+}
+
+// OfflineProvider resolves coordinates to the nearest known place without
+// any network access. Points farther than MaxDistance meters from every
+// place have no result; zero means no limit.
+type OfflineProvider struct {
+	MaxDistance float64
+	tree        *kdTree
+	count       int
+}
+
+// NewOfflineProvider loads places from a GeoNames-format file, or from the
+// bundled sample when path is empty.
+func NewOfflineProvider(path string, maxDistance float64) (*OfflineProvider, error) {
+	var r io.Reader = bytes.NewReader(citiesData)
+	if path != "" {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open geocoder dataset: %w", err)
+		}
+		defer f.Close()
+		r = f
+	}
+
+	places, err := ParsePlaces(r)
+	if err != nil {
+		return nil, err
+	}
+	if len(places) == 0 {
+		return nil, fmt.Errorf("geocoder dataset has no places")
+	}
+	return &OfflineProvider{MaxDistance: maxDistance, tree: newKDTree(places), count: len(places)}, nil
+}
+
+// ParsePlaces reads tab-separated GeoNames geoname rows: name in column 2,
+// latitude/longitude in 5-6, country code in 9, admin1 code in 11 and
+// population in 15. Lines starting with # are skipped.
+func ParsePlaces(r io.Reader) ([]Place, error) {
+	var places []Place
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	line := 0
+	for sc.Scan() {
+		line++
+		text := sc.Text()
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		cols := strings.Split(text, "\t")
+		if len(cols) < 15 {
+			return nil, fmt.Errorf("geocoder dataset line %d: expected at least 15 columns, got %d", line, len(cols))
+		}
+
+		lat, err := strconv.ParseFloat(cols[4], 64)
+		if err != nil {
+			return nil, fmt.Errorf("geocoder dataset line %d: invalid latitude: %w", line, err)
+		}
+		lng, err := strconv.ParseFloat(cols[5], 64)
+		if err != nil {
+			return nil, fmt.Errorf("geocoder dataset line %d: invalid longitude: %w", line, err)
+		}
+		pop, _ := strconv.Atoi(cols[14])
+
+		places = append(places, Place{
+			Name:        cols[1],
+			Lat:         lat,
+			Lng:         lng,
+			CountryCode: cols[8],
+			Admin1:      cols[10],
+			Population:  pop,
+		})
+	}
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read geocoder dataset: %w", err)
+	}
+	return places, nil
+}
+
+func (p *OfflineProvider) Name() string { return "offline" }
+
+func (p *OfflineProvider) Local() bool { return true }
+
+// Len returns the number of indexed places.
+func (p *OfflineProvider) Len() int { return p.count }
+
+func (p *OfflineProvider) Reverse(ctx context.Context, lat, lng float64) (*Address, error) {
+	place, chord2 := p.tree.nearest(lat, lng)
+	if place == nil {
+		return nil, ErrNoResult
+	}
+	// Chord length c on the unit sphere spans the angle 2·asin(c/2).
+	meters := 2 * math.Asin(math.Min(1, math.Sqrt(chord2)/2)) * geo.EarthRadiusMeters
+	if p.MaxDistance > 0 && meters > p.MaxDistance {
+		return nil, ErrNoResult
+	}
+
+	addr := &Address{
+		City:        place.Name,
+		CountryCode: place.CountryCode,
+		Country:     countryNames[place.CountryCode],
+	}
+	// GeoNames admin1 codes are readable abbreviations only for the US.
+	if place.CountryCode == "US" {
+		addr.State = place.Admin1
+	}
+
+	parts := []string{place.Name}
+	if addr.State != "" {
+		parts = append(parts, addr.State)
+	}
+	if addr.Country != "" {
+		parts = append(parts, addr.Country)
+	} else if addr.CountryCode != "" {
+		parts = append(parts, addr.CountryCode)
+	}
+	addr.Formatted = strings.Join(parts, ", ")
+	return addr, nil
+}
+
+var countryNames = map[string]string{
+	"AE": "United Arab Emirates", "AR": "Argentina", "AT": "Austria", "AU": "Australia",
+	"BE": "Belgium", "BR": "Brazil", "CA": "Canada", "CH": "Switzerland", "CL": "Chile",
+	"CN": "China", "CO": "Colombia", "CZ": "Czechia", "DE": "Germany", "DK": "Denmark",
+	"EG": "Egypt", "ES": "Spain", "FI": "Finland", "FR": "France", "GB": "United Kingdom",
+	"GR": "Greece", "HK": "Hong Kong", "ID": "Indonesia", "IE": "Ireland", "IL": "Israel",
+	"IN": "India", "IT": "Italy", "JP": "Japan", "KE": "Kenya", "KR": "South Korea",
+	"MA": "Morocco", "MX": "Mexico", "NG": "Nigeria", "NL": "Netherlands", "NO": "Norway",
+	"NZ": "New Zealand", "PE": "Peru", "PH": "Philippines", "PK": "Pakistan", "PL": "Poland",
+	"PT": "Portugal", "RU": "Russia", "SA": "Saudi Arabia", "SE": "Sweden", "SG": "Singapore",
+	"TH": "Thailand", "TR": "Turkey", "UA": "Ukraine", "US": "United States", "ZA": "South Africa",
+}
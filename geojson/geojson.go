@@ -0,0 +1,174 @@
+// Package geojson converts devices and drive-stop routes to GeoJSON
+// (RFC 7946) feature collections.
+package geojson
+
+import (
+	"slices"
+	"time"
+
+	"github.com/alexbeattie/golangone/drivestop"
+	"github.com/alexbeattie/golangone/models"
+)
+
+// ContentType is the media type for GeoJSON responses.
+const ContentType = "application/geo+json"
+
+// Geometry is a GeoJSON geometry. Positions are [longitude, latitude].
+type Geometry struct {
+	Type        string      `json:"type"`
+	Coordinates interface{} `json:"coordinates"`
+}
+
+// Feature is a GeoJSON feature.
+type Feature struct {
+	Type       string                 `json:"type"`
+	ID         string                 `json:"id,omitempty"`
+	Geometry   *Geometry              `json:"geometry"`
+	Properties map[string]interface{} `json:"properties"`
+}
+
+// FeatureCollection is a GeoJSON feature collection.
+type FeatureCollection struct {
+	Type     string    `json:"type"`
+	Features []Feature `json:"features"`
+}
+
+func newCollection() *FeatureCollection {
+	return &FeatureCollection{Type: "FeatureCollection", Features: []Feature{}}
+}
+
+func point(lat, lng float64) *Geometry {
+	return &Geometry{Type: "Point", Coordinates: [2]float64{lng, lat}}
+}
+
+func lineString(coords [][2]float64) *Geometry {
+	return &Geometry{Type: "LineString", Coordinates: coords}
+}
+
+// Devices returns one Point feature per device at its latest position.
+// Devices that have never reported a position are skipped.
+func Devices(devices []models.Device) *FeatureCollection {
+	fc := newCollection()
+	for _, d := range devices {
+		p := d.LatestDevicePoint
+		if p.Lat == 0 && p.Lng == 0 {
+			continue
+		}
+
+		props := map[string]interface{}{
+			"device_id":    d.DeviceID,
+			"name":         d.DisplayName,
+			"online":       d.Online,
+			"speed":        p.Speed,
+			"heading":      p.Angle,
+			"drive_status": p.DeviceState.DriveStatus,
+			"dt_tracker":   p.DtTracker,
+		}
+		if d.Address != "" {
+			props["address"] = d.Address
+		}
+
+		fc.Features = append(fc.Features, Feature{
+			Type:       "Feature",
+			ID:         d.DeviceID,
+			Geometry:   point(p.Lat, p.Lng),
+			Properties: props,
+		})
+	}
+	return fc
+}
+
+// DriveStops returns a drive-stop route as features: drive segments become
+// LineStrings and stop/idle segments become Points. When points are given,
+// ordered by DtTracker like the route, each drive LineString follows the
+// points recorded during the segment; otherwise it runs straight from the
+// segment's first to last position.
+func DriveStops(route *models.DriveStopResponse, points []models.StoredDevicePoint) *FeatureCollection {
+	fc := newCollection()
+	track := trackCursor{points: points}
+	for _, seg := range route.DriveStopList {
+		props := map[string]interface{}{
+			"type":          seg.Type,
+			"time_from":     seg.TimeFrom,
+			"time_to":       seg.TimeTo,
+			"duration":      seg.Duration,
+			"odometer_from": seg.OdometerFrom,
+			"odometer_to":   seg.OdometerTo,
+		}
+		if seg.Distance != nil {
+			props["distance"] = seg.Distance
+		}
+		if seg.AverageSpeed != nil {
+			props["average_speed"] = seg.AverageSpeed
+		}
+		if seg.TopSpeed != nil {
+			props["top_speed"] = seg.TopSpeed
+		}
+
+		var geom *Geometry
+		if seg.Type == drivestop.TypeDrive {
+			coords := track.segment(seg)
+			if len(coords) < 2 {
+				coords = [][2]float64{
+					{seg.FirstLatLng.Lng, seg.FirstLatLng.Lat},
+					{seg.LastLatLng.Lng, seg.LastLatLng.Lat},
+				}
+			}
+			geom = lineString(coords)
+		} else {
+			geom = point(seg.FirstLatLng.Lat, seg.FirstLatLng.Lng)
+		}
+
+		fc.Features = append(fc.Features, Feature{Type: "Feature", Geometry: geom, Properties: props})
+	}
+	return fc
+}
+
+// trackCursor hands out the points recorded during successive segments in a
+// single pass, relying on both the segments and the points being in time
+// order.
+// RoutePoints returns the points upstream returned with route, ordered by
+// DtTracker, for DriveStops. Points without a valid time are skipped.
+func RoutePoints(route *models.DriveStopResponse) []models.StoredDevicePoint {
+	points := make([]models.StoredDevicePoint, 0, len(route.PointList))
+	for _, p := range route.PointList {
+		dtTracker, err := time.Parse(time.RFC3339, p.DtTracker)
+		if err != nil {
+			continue
+		}
+		points = append(points, models.StoredDevicePoint{DtTracker: dtTracker, Lat: p.Lat, Lng: p.Lng})
+	}
+	slices.SortStableFunc(points, func(a, b models.StoredDevicePoint) int { return a.DtTracker.Compare(b.DtTracker) })
+	return points
+}
+
+type trackCursor struct {
+	points []models.StoredDevicePoint
+	next   int
+}
+
+// segment returns the positions of points recorded within seg's time range.
+// Points before it are skipped for good; the last one can also open the
+// following segment, which starts where this one ends.
+func (c *trackCursor) segment(seg models.DriveStopPoint) [][2]float64 {
+	from, err := time.Parse(time.RFC3339, seg.TimeFrom)
+	if err != nil {
+		return nil
+	}
+	to, err := time.Parse(time.RFC3339, seg.TimeTo)
+	if err != nil {
+		return nil
+	}
+
+	for c.next < len(c.points) && c.points[c.next].DtTracker.Before(from) {
+		c.next++
+	}
+	var coords [][2]float64
+	for _, p := range c.points[c.next:] {
+		if p.DtTracker.After(to) {
+			break
+		}
+		coords = append(coords, [2]float64{p.Lng, p.Lat})
+	}
+	return coords
+}
@@ -0,0 +1,135 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"github.com/alexbeattie/golangone/models"
+	"github.com/alexbeattie/golangone/services"
+)
+
+// respondAlertError maps alert service errors to HTTP responses.
+func respondAlertError(c *gin.Context, message string, err error) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Not found"})
+	case errors.Is(err, services.ErrInvalidAlertRule):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrAlertResolved):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": message})
+	}
+}
+
+func (h *Handler) ListAlertRules(c *gin.Context) {
+	rules, err := h.service.ListAlertRules(c.Param("userId"))
+	if err != nil {
+		respondAlertError(c, "Failed to fetch alert rules", err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"rules": rules})
+}
+
+func (h *Handler) GetAlertRule(c *gin.Context) {
+	id, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+	rule, err := h.service.GetAlertRule(c.Param("userId"), id)
+	if err != nil {
+		respondAlertError(c, "Failed to fetch alert rule", err)
+		return
+	}
+	c.JSON(http.StatusOK, rule)
+}
+
+func (h *Handler) CreateAlertRule(c *gin.Context) {
+	rule := models.AlertRule{Enabled: true}
+	if err := c.ShouldBindJSON(&rule); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	rule.ID = 0
+	rule.UserID = c.Param("userId")
+
+	if err := h.service.CreateAlertRule(&rule); err != nil {
+		respondAlertError(c, "Failed to create alert rule", err)
+		return
+	}
+	c.JSON(http.StatusCreated, rule)
+}
+
+func (h *Handler) UpdateAlertRule(c *gin.Context) {
+	id, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+	rule := models.AlertRule{Enabled: true}
+	if err := c.ShouldBindJSON(&rule); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	rule.ID = id
+	rule.UserID = c.Param("userId")
+
+	if err := h.service.UpdateAlertRule(&rule); err != nil {
+		respondAlertError(c, "Failed to update alert rule", err)
+		return
+	}
+	c.JSON(http.StatusOK, rule)
+}
+
+func (h *Handler) DeleteAlertRule(c *gin.Context) {
+	id, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+	if err := h.service.DeleteAlertRule(c.Param("userId"), id); err != nil {
+		respondAlertError(c, "Failed to delete alert rule", err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// ListAlerts returns a user's alerts. Query parameters: status, device_id and limit.
+func (h *Handler) ListAlerts(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.Query("limit"))
+	alerts, err := h.service.ListAlerts(c.Param("userId"), c.Query("status"), c.Query("device_id"), limit)
+	if err != nil {
+		respondAlertError(c, "Failed to fetch alerts", err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"alerts": alerts})
+}
+
+func (h *Handler) AcknowledgeAlert(c *gin.Context) {
+	id, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+	userID := c.Param("userId")
+	alert, err := h.service.AcknowledgeAlert(userID, id, userID)
+	if err != nil {
+		respondAlertError(c, "Failed to acknowledge alert", err)
+		return
+	}
+	c.JSON(http.StatusOK, alert)
+}
+
+func (h *Handler) ResolveAlert(c *gin.Context) {
+	id, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+	alert, err := h.service.ResolveAlert(c.Param("userId"), id)
+	if err != nil {
+		respondAlertError(c, "Failed to resolve alert", err)
+		return
+	}
+	c.JSON(http.StatusOK, alert)
+}
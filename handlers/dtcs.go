@@ -0,0 +1,18 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetDeviceDTCs lists a device's diagnostic trouble codes with their
+// first-seen, last-seen and cleared times. Pass active=true to hide cleared codes.
+func (h *Handler) GetDeviceDTCs(c *gin.Context) {
+	codes, err := h.service.ListDeviceDTCs(c.Param("deviceId"), c.Query("active") == "true")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch trouble codes"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"dtcs": codes})
+}
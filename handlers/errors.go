@@ -0,0 +1,54 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/alexbeattie/golangone/services"
+	"github.com/gin-gonic/gin"
+)
+
+// Stable error codes returned in the "code" field of error responses.
+const (
+	codeUpstreamAuth        = "upstream_auth_failed"
+	codeUpstreamRateLimited = "upstream_rate_limited"
+	codeUpstreamUnavailable = "upstream_unavailable"
+	codeUpstreamRejected    = "upstream_rejected"
+	codeUpstreamMalformed   = "upstream_malformed_response"
+	codeInternal            = "internal_error"
+)
+
+// respondUpstreamError maps an error from the tracking provider to an HTTP
+// status and a JSON body with a stable error code. message is the
+// user-facing summary; the underlying error is only logged.
+func respondUpstreamError(c *gin.Context, message string, err error) {
+	status, code := http.StatusInternalServerError, codeInternal
+
+	switch {
+	case errors.Is(err, services.ErrUpstreamAuth):
+		status, code = http.StatusUnauthorized, codeUpstreamAuth
+	case errors.Is(err, services.ErrUpstreamRateLimited):
+		status, code = http.StatusServiceUnavailable, codeUpstreamRateLimited
+	case errors.Is(err, services.ErrUpstreamUnavailable):
+		status, code = http.StatusServiceUnavailable, codeUpstreamUnavailable
+	case errors.Is(err, services.ErrUpstreamRejected):
+		status, code = http.StatusBadGateway, codeUpstreamRejected
+	case errors.Is(err, services.ErrUpstreamMalformed):
+		status, code = http.StatusBadGateway, codeUpstreamMalformed
+	}
+
+	// Log the operation and status rather than err itself: the request URL
+	// carries the API key. Transport causes have their query stripped.
+	var upErr *services.UpstreamError
+	if errors.As(err, &upErr) {
+		cause := ""
+		if upErr.Err != nil {
+			cause = ": " + upErr.Err.Error()
+		}
+		log.Printf("%s: %s: %v (status %d)%s", message, upErr.Op, upErr.Kind, upErr.StatusCode, cause)
+	} else {
+		log.Printf("%s: %v", message, err)
+	}
+	c.JSON(status, gin.H{"error": message, "code": code})
+}
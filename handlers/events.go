@@ -0,0 +1,53 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/alexbeattie/golangone/services"
+)
+
+// ListEvents returns stored events, newest first. Query parameters: type,
+// subtype, device_id, geofence_id, from, to (RFC3339) and limit.
+func (h *Handler) ListEvents(c *gin.Context) {
+	q := services.EventQuery{
+		Type:     c.Query("type"),
+		Subtype:  c.Query("subtype"),
+		DeviceID: c.Query("device_id"),
+	}
+
+	if v := c.Query("geofence_id"); v != "" {
+		id, err := strconv.ParseUint(v, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid geofence_id"})
+			return
+		}
+		q.GeofenceID = uint(id)
+	}
+	if c.Query("from") != "" || c.Query("to") != "" {
+		from, to, err := parseTimeRange(c, 24*time.Hour)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		q.From, q.To = from, to
+	}
+	if v := c.Query("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be a positive integer"})
+			return
+		}
+		q.Limit = limit
+	}
+
+	events, err := h.service.ListEvents(q)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch events"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"events": events})
+}
@@ -0,0 +1,103 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"github.com/alexbeattie/golangone/models"
+	"github.com/alexbeattie/golangone/reports"
+	"github.com/alexbeattie/golangone/services"
+)
+
+// respondFuelError maps fuel service errors to HTTP responses.
+func respondFuelError(c *gin.Context, message string, err error) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Not found"})
+	case errors.Is(err, services.ErrInvalidFuelOverride):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": message})
+	}
+}
+
+// GetFuelReport estimates fuel used and its cost from distance driven and
+// idle time. It takes the trip export parameters plus period ("day", "week"
+// or "month" in tz; default one row per device for the whole range).
+func (h *Handler) GetFuelReport(c *gin.Context) {
+	q, ok := parseReportQuery(c)
+	if !ok {
+		return
+	}
+
+	period := c.Query("period")
+	switch period {
+	case reports.PeriodAll, reports.PeriodDay, reports.PeriodWeek, reports.PeriodMonth:
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "period must be day, week or month"})
+		return
+	}
+
+	rows, err := h.service.FuelReport(c.Request.Context(), q.deviceID, q.from, q.to, q.opts, period, q.loc)
+	if err != nil {
+		respondFuelError(c, "Failed to build fuel report", err)
+		return
+	}
+
+	distanceUnit, volumeUnit := "km", "l"
+	if q.opts.Imperial {
+		distanceUnit, volumeUnit = "mi", "gal"
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"from":          q.from,
+		"to":            q.to,
+		"distance_unit": distanceUnit,
+		"volume_unit":   volumeUnit,
+		"rows":          rows,
+	})
+}
+
+// GetFuelSettings shows a device's upstream fuel settings, its override and
+// the effective profile used by fuel reports.
+func (h *Handler) GetFuelSettings(c *gin.Context) {
+	settings, err := h.service.FuelSettings(c.Param("deviceId"))
+	if err != nil {
+		respondFuelError(c, "Failed to fetch fuel settings", err)
+		return
+	}
+	c.JSON(http.StatusOK, settings)
+}
+
+// SetFuelOverride replaces a device's fuel override and returns the
+// resulting settings.
+func (h *Handler) SetFuelOverride(c *gin.Context) {
+	var override models.FuelOverride
+	if err := c.ShouldBindJSON(&override); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	override.DeviceID = c.Param("deviceId")
+
+	if err := h.service.SetFuelOverride(&override); err != nil {
+		respondFuelError(c, "Failed to save fuel override", err)
+		return
+	}
+	settings, err := h.service.FuelSettings(override.DeviceID)
+	if err != nil {
+		respondFuelError(c, "Failed to fetch fuel settings", err)
+		return
+	}
+	c.JSON(http.StatusOK, settings)
+}
+
+// DeleteFuelOverride reverts a device to its upstream fuel settings.
+func (h *Handler) DeleteFuelOverride(c *gin.Context) {
+	if err := h.service.DeleteFuelOverride(c.Param("deviceId")); err != nil {
+		respondFuelError(c, "Failed to delete fuel override", err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
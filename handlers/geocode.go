@@ -0,0 +1,44 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/alexbeattie/golangone/geocode"
+	"github.com/alexbeattie/golangone/services"
+	"github.com/gin-gonic/gin"
+)
+
+// addressLookupTimeout bounds how long a device list waits for addresses;
+// devices not resolved in time are returned without one.
+const addressLookupTimeout = 5 * time.Second
+
+// ReverseGeocode returns the address at ?lat=&lng=.
+func (h *Handler) ReverseGeocode(c *gin.Context) {
+	lat, err := strconv.ParseFloat(c.Query("lat"), 64)
+	if err != nil || lat < -90 || lat > 90 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid lat"})
+		return
+	}
+	lng, err := strconv.ParseFloat(c.Query("lng"), 64)
+	if err != nil || lng < -180 || lng > 180 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid lng"})
+		return
+	}
+
+	addr, err := h.service.ReverseGeocode(c.Request.Context(), lat, lng)
+	switch {
+	case errors.Is(err, services.ErrGeocodingDisabled):
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+	case errors.Is(err, geocode.ErrNoResult):
+		c.JSON(http.StatusNotFound, gin.H{"error": "No address found"})
+	case err != nil:
+		log.Printf("Failed to reverse geocode %f,%f: %v", lat, lng, err)
+		c.JSON(http.StatusBadGateway, gin.H{"error": "Failed to reverse geocode"})
+	default:
+		c.JSON(http.StatusOK, addr)
+	}
+}
@@ -0,0 +1,122 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"github.com/alexbeattie/golangone/models"
+	"github.com/alexbeattie/golangone/services"
+)
+
+// parseID reads a positive numeric path parameter, writing a 400 if it is invalid.
+func parseID(c *gin.Context, name string) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param(name), 10, 64)
+	if err != nil || id == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid " + name})
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// respondGeofenceError maps geofence service errors to HTTP responses.
+func respondGeofenceError(c *gin.Context, message string, err error) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Geofence not found"})
+	case errors.Is(err, services.ErrInvalidGeofence):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": message})
+	}
+}
+
+func (h *Handler) ListGeofences(c *gin.Context) {
+	geofences, err := h.service.ListGeofences()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch geofences"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"geofences": geofences})
+}
+
+func (h *Handler) GetGeofence(c *gin.Context) {
+	id, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+	g, err := h.service.GetGeofence(id)
+	if err != nil {
+		respondGeofenceError(c, "Failed to fetch geofence", err)
+		return
+	}
+	c.JSON(http.StatusOK, g)
+}
+
+func (h *Handler) CreateGeofence(c *gin.Context) {
+	var g models.Geofence
+	if err := c.ShouldBindJSON(&g); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	g.ID = 0
+
+	if err := h.service.CreateGeofence(&g); err != nil {
+		respondGeofenceError(c, "Failed to create geofence", err)
+		return
+	}
+	c.JSON(http.StatusCreated, g)
+}
+
+func (h *Handler) UpdateGeofence(c *gin.Context) {
+	id, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+	var g models.Geofence
+	if err := c.ShouldBindJSON(&g); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	g.ID = id
+
+	if err := h.service.UpdateGeofence(&g); err != nil {
+		respondGeofenceError(c, "Failed to update geofence", err)
+		return
+	}
+	c.JSON(http.StatusOK, g)
+}
+
+func (h *Handler) DeleteGeofence(c *gin.Context) {
+	id, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+	if err := h.service.DeleteGeofence(id); err != nil {
+		respondGeofenceError(c, "Failed to delete geofence", err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// GetGeofenceDevices lists the devices whose latest point is inside the geofence.
+func (h *Handler) GetGeofenceDevices(c *gin.Context) {
+	id, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+	devices, err := h.service.DevicesInGeofence(id)
+	if err != nil {
+		var upErr *services.UpstreamError
+		if errors.As(err, &upErr) {
+			respondUpstreamError(c, "Failed to fetch devices", err)
+			return
+		}
+		respondGeofenceError(c, "Failed to fetch geofence devices", err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"devices": devices})
+}
@@ -0,0 +1,14 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/alexbeattie/golangone/geojson"
+	"github.com/gin-gonic/gin"
+)
+
+// renderGeoJSON writes fc with the GeoJSON media type.
+func renderGeoJSON(c *gin.Context, fc *geojson.FeatureCollection) {
+	c.Header("Content-Type", geojson.ContentType)
+	c.JSON(http.StatusOK, fc)
+}
@@ -2,13 +2,15 @@ package handlers
 
 import (
 	// "log"
+	"context"
 	"errors"
 	"net/http"
-	// "strconv"
+	"strconv"
 	"time"
 
 	"gorm.io/gorm"
 
+	"github.com/alexbeattie/golangone/geojson"
 	"github.com/alexbeattie/golangone/models"
 	"github.com/alexbeattie/golangone/services"
 	"github.com/gin-gonic/gin"
@@ -110,20 +112,61 @@ func (h *Handler) UpdateUserPreferences(c *gin.Context) {
 }
 
 
+// wantsAddresses reports whether GetDevices should reverse geocode. An
+// explicit ?address= wins; otherwise the ShowAddress preference of ?user_id=
+// applies, on by default like the rest of the display preferences. Without
+// either, addresses are left out since lookups can be slow.
+func (h *Handler) wantsAddresses(c *gin.Context) (bool, error) {
+	if q := c.Query("address"); q != "" {
+		return q == "true", nil
+	}
+	userID := c.Query("user_id")
+	if userID == "" {
+		return false, nil
+	}
+	prefs, err := h.userPreferences(userID)
+	if err != nil {
+		return false, err
+	}
+	return prefs == nil || prefs.ShowAddress, nil
+}
+
 func (h *Handler) GetDevices(c *gin.Context) {
-	devices, err := h.service.FetchDevices()
+	snap, err := h.service.DeviceSnapshot()
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch devices"})
+		respondUpstreamError(c, "Failed to fetch devices", err)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"devices": devices})
+	withAddresses, err := h.wantsAddresses(c)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch preferences"})
+		return
+	}
+
+	devices := snap.Devices
+	if withAddresses {
+		ctx, cancel := context.WithTimeout(c.Request.Context(), addressLookupTimeout)
+		devices = h.service.WithAddresses(ctx, devices)
+		cancel()
+	}
+
+	c.Header("Age", strconv.Itoa(int(time.Since(snap.FetchedAt).Seconds())))
+	if c.Query("format") == "geojson" {
+		renderGeoJSON(c, geojson.Devices(devices))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"devices":    devices,
+		"fetched_at": snap.FetchedAt,
+		"stale":      snap.Stale,
+	})
 }
 func (h *Handler) GetDeviceInfo(c *gin.Context) {
     // You can add query params handling if needed
-    deviceInfo, err := h.service.FetchDeviceInfo(nil)
+    deviceInfo, err := h.service.FetchDeviceInfo(c.Request.Context(), nil)
     if err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+        respondUpstreamError(c, "Failed to fetch device info", err)
         return
     }
 
@@ -152,11 +195,33 @@ func (h *Handler) GetDriveStopRoute(c *gin.Context) {
         return
     }
 
-    routeData, err := h.service.FetchDriveStopRoute(deviceID, from, to, stopDuration)
+    routeData, err := h.service.FetchDriveStopRoute(c.Request.Context(), deviceID, from, to, stopDuration)
     if err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+        respondUpstreamError(c, "Failed to fetch drive-stop route", err)
         return
     }
 
+    if c.Query("format") == "geojson" {
+        renderGeoJSON(c, geojson.DriveStops(routeData, geojson.RoutePoints(routeData)))
+        return
+    }
     c.JSON(http.StatusOK, routeData)
-}
\ No newline at end of file
+}
+
+// GetHealth reports server health along with the upstream circuit breaker
+// state. It always answers 200 so an upstream outage does not take this
+// instance out of rotation; "status" is "degraded" while the breaker is open.
+func (h *Handler) GetHealth(c *gin.Context) {
+	status := "ok"
+	body := gin.H{}
+
+	if breaker, ok := h.service.UpstreamBreaker(); ok {
+		if breaker.State != services.BreakerClosed {
+			status = "degraded"
+		}
+		body["upstream"] = breaker
+	}
+
+	body["status"] = status
+	c.JSON(http.StatusOK, body)
+}
@@ -0,0 +1,105 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/alexbeattie/golangone/drivestop"
+	"github.com/alexbeattie/golangone/geojson"
+	"github.com/alexbeattie/golangone/services"
+	"github.com/gin-gonic/gin"
+)
+
+// GetDeviceHistory serves stored positions for a device from our own database.
+//
+// Query parameters: from, to (RFC3339, default last 24h), cursor, limit
+// (default 1000, max 5000), interval (Go duration, e.g. "1m") and
+// min_distance (meters) to downsample.
+func (h *Handler) GetDeviceHistory(c *gin.Context) {
+	from, to, err := parseTimeRange(c, 24*time.Hour)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	q := services.HistoryQuery{
+		DeviceID: c.Param("deviceId"),
+		From:     from,
+		To:       to,
+		Cursor:   c.Query("cursor"),
+	}
+
+	if v := c.Query("limit"); v != "" {
+		if q.Limit, err = strconv.Atoi(v); err != nil || q.Limit <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be a positive integer"})
+			return
+		}
+	}
+	if v := c.Query("interval"); v != "" {
+		if q.Interval, err = time.ParseDuration(v); err != nil || q.Interval < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid interval"})
+			return
+		}
+	}
+	if v := c.Query("min_distance"); v != "" {
+		if q.MinDistance, err = strconv.ParseFloat(v, 64); err != nil || q.MinDistance < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid min_distance"})
+			return
+		}
+	}
+
+	page, err := h.service.QueryHistory(q)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidCursor) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch history"})
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+}
+
+// GetLocalDriveStops computes drive/stop segments from stored history instead
+// of asking the upstream. Query parameters: from, to (RFC3339, default last
+// 24h), stop_duration (minimum stop, default "5m") and units ("mi" or "km").
+func (h *Handler) GetLocalDriveStops(c *gin.Context) {
+	from, to, err := parseTimeRange(c, 24*time.Hour)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	minStop, err := time.ParseDuration(c.DefaultQuery("stop_duration", "5m"))
+	if err != nil || minStop < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid stop_duration"})
+		return
+	}
+
+	units := c.DefaultQuery("units", "mi")
+	if units != "mi" && units != "km" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "units must be mi or km"})
+		return
+	}
+
+	opts := drivestop.Options{MinStopDuration: minStop, Imperial: units == "mi"}
+	routeData, err := h.service.ComputeDriveStops(c.Request.Context(), c.Param("deviceId"), from, to, opts)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to compute drive-stop route"})
+		return
+	}
+
+	if c.Query("format") == "geojson" {
+		points, err := h.service.StoredPoints(c.Param("deviceId"), from, to)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load route points"})
+			return
+		}
+		renderGeoJSON(c, geojson.DriveStops(routeData, points))
+		return
+	}
+	c.JSON(http.StatusOK, routeData)
+}
@@ -0,0 +1,35 @@
+package handlers
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// parseTimeRange reads the RFC3339 "from" and "to" query parameters. "to"
+// defaults to now and "from" to defaultSpan before "to".
+func parseTimeRange(c *gin.Context, defaultSpan time.Duration) (time.Time, time.Time, error) {
+	to := time.Now().UTC()
+	if v := c.Query("to"); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("invalid to date format")
+		}
+		to = t
+	}
+
+	from := to.Add(-defaultSpan)
+	if v := c.Query("from"); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("invalid from date format")
+		}
+		from = t
+	}
+
+	if from.After(to) {
+		return time.Time{}, time.Time{}, fmt.Errorf("from must be before to")
+	}
+	return from, to, nil
+}
@@ -0,0 +1,136 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"github.com/alexbeattie/golangone/models"
+	"github.com/alexbeattie/golangone/services"
+)
+
+// respondReportScheduleError maps report schedule service errors to HTTP responses.
+func respondReportScheduleError(c *gin.Context, message string, err error) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Not found"})
+	case errors.Is(err, services.ErrInvalidReportSchedule):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrReportRunning):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrEmailNotConfigured):
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": message})
+	}
+}
+
+func (h *Handler) ListReportSchedules(c *gin.Context) {
+	schedules, err := h.service.ListReportSchedules(c.Param("userId"))
+	if err != nil {
+		respondReportScheduleError(c, "Failed to fetch report schedules", err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"schedules": schedules})
+}
+
+func (h *Handler) GetReportSchedule(c *gin.Context) {
+	id, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+	schedule, err := h.service.GetReportSchedule(c.Param("userId"), id)
+	if err != nil {
+		respondReportScheduleError(c, "Failed to fetch report schedule", err)
+		return
+	}
+	c.JSON(http.StatusOK, schedule)
+}
+
+func (h *Handler) CreateReportSchedule(c *gin.Context) {
+	schedule := models.ReportSchedule{Enabled: true}
+	if err := c.ShouldBindJSON(&schedule); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	schedule.ID = 0
+	schedule.UserID = c.Param("userId")
+
+	if err := h.service.CreateReportSchedule(&schedule); err != nil {
+		respondReportScheduleError(c, "Failed to create report schedule", err)
+		return
+	}
+	c.JSON(http.StatusCreated, schedule)
+}
+
+func (h *Handler) UpdateReportSchedule(c *gin.Context) {
+	id, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+	schedule := models.ReportSchedule{Enabled: true}
+	if err := c.ShouldBindJSON(&schedule); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	schedule.ID = id
+	schedule.UserID = c.Param("userId")
+
+	if err := h.service.UpdateReportSchedule(&schedule); err != nil {
+		respondReportScheduleError(c, "Failed to update report schedule", err)
+		return
+	}
+	c.JSON(http.StatusOK, schedule)
+}
+
+func (h *Handler) DeleteReportSchedule(c *gin.Context) {
+	id, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+	if err := h.service.DeleteReportSchedule(c.Param("userId"), id); err != nil {
+		respondReportScheduleError(c, "Failed to delete report schedule", err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// RunReportSchedule runs a schedule now for its last completed period and
+// returns the recorded run, which may have failed.
+func (h *Handler) RunReportSchedule(c *gin.Context) {
+	id, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+	run, err := h.service.RunReportNow(c.Request.Context(), c.Param("userId"), id)
+	if err != nil {
+		respondReportScheduleError(c, "Failed to run report", err)
+		return
+	}
+	c.JSON(http.StatusOK, run)
+}
+
+// ListReportRuns shows a user's run history. Query parameters: schedule_id,
+// status ("running", "succeeded" or "failed") and limit.
+func (h *Handler) ListReportRuns(c *gin.Context) {
+	var scheduleID uint
+	if v := c.Query("schedule_id"); v != "" {
+		id, err := strconv.ParseUint(v, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid schedule_id"})
+			return
+		}
+		scheduleID = uint(id)
+	}
+	limit, _ := strconv.Atoi(c.Query("limit"))
+
+	runs, err := h.service.ListReportRuns(c.Param("userId"), scheduleID, c.Query("status"), limit)
+	if err != nil {
+		respondReportScheduleError(c, "Failed to fetch report runs", err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"runs": runs})
+}
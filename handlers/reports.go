@@ -0,0 +1,65 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/alexbeattie/golangone/reports"
+	"github.com/alexbeattie/golangone/services"
+)
+
+// GetSpeedingReport lists speeding incidents. Query parameters: device_id
+// (default all devices), from, to (RFC3339, default last 7 days) and min_over
+// (mph over the posted limit, default 5).
+func (h *Handler) GetSpeedingReport(c *gin.Context) {
+	from, to, err := parseTimeRange(c, 7*24*time.Hour)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	opts := reports.DefaultSpeedingOptions
+	if v := c.Query("min_over"); v != "" {
+		if opts.MinMphOver, err = strconv.ParseFloat(v, 64); err != nil || opts.MinMphOver < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid min_over"})
+			return
+		}
+	}
+
+	incidents, err := h.service.SpeedingReport(c.Query("device_id"), from, to, opts)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to build speeding report"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"from":      from,
+		"to":        to,
+		"incidents": incidents,
+	})
+}
+
+// GetScorecards ranks the fleet by weekly safety score. The week query
+// parameter (YYYY-MM-DD, any day of the week) defaults to the last full week.
+func (h *Handler) GetScorecards(c *gin.Context) {
+	week := services.WeekStart(time.Now()).AddDate(0, 0, -7)
+	if v := c.Query("week"); v != "" {
+		t, err := time.Parse("2006-01-02", v)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "week must be YYYY-MM-DD"})
+			return
+		}
+		week = services.WeekStart(t)
+	}
+
+	scores, err := h.service.Scorecards(week)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to build scorecards"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"week_start": week, "scorecards": scores})
+}
@@ -0,0 +1,87 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/alexbeattie/golangone/stream"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	streamBuffer    = 64
+	streamHeartbeat = 15 * time.Second
+)
+
+// StreamDevices pushes device changes as Server-Sent Events. Each "device"
+// event carries a stream.Event with its ID as the SSE id, so browsers resume
+// automatically via Last-Event-ID (or the last_event_id query parameter).
+// If the requested events have expired a "reset" event tells the client to
+// refetch /api/v1/devices before applying further diffs.
+func (h *Handler) StreamDevices(c *gin.Context) {
+	lastID := c.GetHeader("Last-Event-ID")
+	if lastID == "" {
+		lastID = c.Query("last_event_id")
+	}
+
+	sub, replay, err := h.service.DeviceHub().Subscribe(lastID, streamBuffer)
+	if errors.Is(err, stream.ErrInvalidEventID) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid last event id"})
+		return
+	}
+	defer sub.Close()
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+
+	w := c.Writer
+	fmt.Fprintf(w, "retry: %d\n\n", 3000)
+	if errors.Is(err, stream.ErrResumeGap) {
+		fmt.Fprint(w, "event: reset\ndata: {}\n\n")
+	}
+	for _, ev := range replay {
+		if writeDeviceEvent(w, ev) != nil {
+			return
+		}
+	}
+	w.Flush()
+
+	heartbeat := time.NewTicker(streamHeartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case ev, ok := <-sub.C:
+			if !ok {
+				// Dropped for falling behind; the client reconnects and resumes.
+				return
+			}
+			if writeDeviceEvent(w, ev) != nil {
+				return
+			}
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(w, ": ping\n\n"); err != nil {
+				return
+			}
+		}
+		w.Flush()
+	}
+}
+
+func writeDeviceEvent(w io.Writer, ev stream.Event) error {
+	data, err := json.Marshal(ev)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "id: %s\nevent: device\ndata: %s\n\n", ev.ID, data)
+	return err
+}
@@ -0,0 +1,84 @@
+package handlers
+
+import (
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"time"
+
+	"github.com/alexbeattie/golangone/drivestop"
+	"github.com/alexbeattie/golangone/export"
+	"github.com/alexbeattie/golangone/models"
+	"github.com/gin-gonic/gin"
+)
+
+// GetTrackGPX streams a device's stored track for ?from=&to= as GPX.
+func (h *Handler) GetTrackGPX(c *gin.Context) {
+	h.serveTrack(c, "gpx", export.GPXContentType, export.WriteGPX)
+}
+
+// GetTrackKML streams a device's stored track for ?from=&to= as KML.
+func (h *Handler) GetTrackKML(c *gin.Context) {
+	h.serveTrack(c, "kml", export.KMLContentType, export.WriteKML)
+}
+
+// serveTrack segments the window first so stops can be written ahead of the
+// points, then streams the points straight from the database to the client.
+func (h *Handler) serveTrack(c *gin.Context, ext, contentType string, write func(io.Writer, export.Track) error) {
+	from, to, err := parseTimeRange(c, 24*time.Hour)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	minStop, err := time.ParseDuration(c.DefaultQuery("stop_duration", "5m"))
+	if err != nil || minStop < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid stop_duration"})
+		return
+	}
+
+	units := c.DefaultQuery("units", "mi")
+	if units != "mi" && units != "km" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "units must be mi or km"})
+		return
+	}
+
+	ctx := c.Request.Context()
+	deviceID := c.Param("deviceId")
+	track := export.Track{
+		Name:     h.service.DeviceName(deviceID),
+		Imperial: units == "mi",
+		Points:   func(func(models.StoredDevicePoint) error) error { return nil },
+	}
+
+	// The points are read several times (KML writes each list in its own
+	// pass), so end the window at the newest point already stored; points
+	// the ingestor adds meanwhile then can't make the passes disagree.
+	latest, ok, err := h.service.LatestPointTime(ctx, deviceID, from, to)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load track"})
+		return
+	}
+	if ok {
+		to = latest
+		opts := drivestop.Options{MinStopDuration: minStop, Imperial: track.Imperial}
+		route, err := h.service.ComputeDriveStops(c.Request.Context(), deviceID, from, to, opts)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to compute drive-stop route"})
+			return
+		}
+		track.Segments = route.DriveStopList
+		track.Points = func(fn func(models.StoredDevicePoint) error) error {
+			return h.service.EachStoredPoint(ctx, deviceID, from, to, fn)
+		}
+	}
+
+	c.Header("Content-Type", contentType)
+	c.Header("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": deviceID + "." + ext}))
+	c.Status(http.StatusOK)
+	if err := write(c.Writer, track); err != nil {
+		// Headers are already sent; the client sees a truncated document.
+		log.Printf("Failed to write %s track for device %s: %v", ext, deviceID, err)
+	}
+}
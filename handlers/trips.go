@@ -0,0 +1,132 @@
+package handlers
+
+import (
+	"bytes"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/alexbeattie/golangone/drivestop"
+	"github.com/alexbeattie/golangone/export"
+	"github.com/alexbeattie/golangone/reports"
+)
+
+// maxTripReportSpan bounds trip exports so a fleet-wide request stays cheap.
+const maxTripReportSpan = 93 * 24 * time.Hour
+
+// GetTripsCSV exports drive-stop segments as CSV.
+func (h *Handler) GetTripsCSV(c *gin.Context) {
+	h.serveTrips(c, "csv", export.CSVContentType, export.WriteTripsCSV)
+}
+
+// GetTripsXLSX exports drive-stop segments as an Excel workbook.
+func (h *Handler) GetTripsXLSX(c *gin.Context) {
+	h.serveTrips(c, "xlsx", export.XLSXContentType, export.WriteTripsXLSX)
+}
+
+// reportQuery holds the query parameters shared by the report exports.
+type reportQuery struct {
+	deviceID string
+	from, to time.Time
+	opts     drivestop.Options
+	loc      *time.Location
+}
+
+// parseReportQuery reads device_id (default all devices), from, to (RFC3339,
+// default last 7 days), units (mi or km), tz (IANA timezone for timestamps,
+// default UTC) and stop_duration. On failure it writes a 400 and returns false.
+func parseReportQuery(c *gin.Context) (reportQuery, bool) {
+	from, to, err := parseTimeRange(c, 7*24*time.Hour)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return reportQuery{}, false
+	}
+	if to.Sub(from) > maxTripReportSpan {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "date range is limited to 93 days"})
+		return reportQuery{}, false
+	}
+
+	minStop, err := time.ParseDuration(c.DefaultQuery("stop_duration", "5m"))
+	if err != nil || minStop < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid stop_duration"})
+		return reportQuery{}, false
+	}
+
+	units := c.DefaultQuery("units", "mi")
+	if units != "mi" && units != "km" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "units must be mi or km"})
+		return reportQuery{}, false
+	}
+
+	loc, err := time.LoadLocation(c.DefaultQuery("tz", "UTC"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid tz"})
+		return reportQuery{}, false
+	}
+
+	return reportQuery{
+		deviceID: c.Query("device_id"),
+		from:     from,
+		to:       to,
+		opts:     drivestop.Options{MinStopDuration: minStop, Imperial: units == "mi"},
+		loc:      loc,
+	}, true
+}
+
+// sendAttachment sends a rendered report as a download.
+func sendAttachment(c *gin.Context, filename, contentType string, body []byte) {
+	c.Header("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": filename}))
+	c.Data(http.StatusOK, contentType, body)
+}
+
+// serveTrips handles the trip exports; see parseReportQuery for parameters.
+func (h *Handler) serveTrips(c *gin.Context, ext, contentType string, write func(io.Writer, []reports.TripRow, export.TableOptions) error) {
+	q, ok := parseReportQuery(c)
+	if !ok {
+		return
+	}
+
+	trips, err := h.service.TripReport(c.Request.Context(), q.deviceID, q.from, q.to, q.opts)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to build trip report"})
+		return
+	}
+
+	// Render before sending headers so a failure can still be reported as JSON.
+	var buf bytes.Buffer
+	if err := write(&buf, reports.TripRows(trips, q.opts.Imperial), export.TableOptions{Imperial: q.opts.Imperial, Location: q.loc}); err != nil {
+		log.Printf("Failed to write %s trip report: %v", ext, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to write trip report"})
+		return
+	}
+
+	sendAttachment(c, export.TripsFilename(q.deviceID, q.from.In(q.loc), q.to.In(q.loc), ext), contentType, buf.Bytes())
+}
+
+// GetFleetSummaryPDF renders distance, drive, idle and stop totals with a
+// route thumbnail per device as a PDF. It takes the trip export parameters.
+func (h *Handler) GetFleetSummaryPDF(c *gin.Context) {
+	q, ok := parseReportQuery(c)
+	if !ok {
+		return
+	}
+
+	summary, err := h.service.FleetSummary(c.Request.Context(), q.deviceID, q.from, q.to, q.opts)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to build fleet summary"})
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := export.WriteFleetSummaryPDF(&buf, summary, q.loc); err != nil {
+		log.Printf("Failed to write fleet summary PDF: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to write fleet summary"})
+		return
+	}
+
+	sendAttachment(c, export.SummaryFilename(q.deviceID, q.from.In(q.loc), q.to.In(q.loc)), export.PDFContentType, buf.Bytes())
+}
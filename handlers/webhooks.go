@@ -0,0 +1,150 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"github.com/alexbeattie/golangone/models"
+	"github.com/alexbeattie/golangone/services"
+)
+
+// respondWebhookError maps webhook service errors to HTTP responses.
+func respondWebhookError(c *gin.Context, message string, err error) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Not found"})
+	case errors.Is(err, services.ErrInvalidWebhook):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": message})
+	}
+}
+
+func (h *Handler) ListWebhooks(c *gin.Context) {
+	hooks, err := h.service.ListWebhooks()
+	if err != nil {
+		respondWebhookError(c, "Failed to fetch webhooks", err)
+		return
+	}
+	// Secrets are only shown when a subscription is created.
+	for i := range hooks {
+		hooks[i].Secret = ""
+	}
+	c.JSON(http.StatusOK, gin.H{"webhooks": hooks})
+}
+
+func (h *Handler) GetWebhook(c *gin.Context) {
+	id, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+	hook, err := h.service.GetWebhook(id)
+	if err != nil {
+		respondWebhookError(c, "Failed to fetch webhook", err)
+		return
+	}
+	hook.Secret = ""
+	c.JSON(http.StatusOK, hook)
+}
+
+func (h *Handler) CreateWebhook(c *gin.Context) {
+	hook := models.WebhookSubscription{Enabled: true}
+	if err := c.ShouldBindJSON(&hook); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	hook.ID = 0
+
+	if err := h.service.CreateWebhook(&hook); err != nil {
+		respondWebhookError(c, "Failed to create webhook", err)
+		return
+	}
+	c.JSON(http.StatusCreated, hook)
+}
+
+func (h *Handler) UpdateWebhook(c *gin.Context) {
+	id, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+	hook := models.WebhookSubscription{Enabled: true}
+	if err := c.ShouldBindJSON(&hook); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	hook.ID = id
+
+	if err := h.service.UpdateWebhook(&hook); err != nil {
+		respondWebhookError(c, "Failed to update webhook", err)
+		return
+	}
+	hook.Secret = ""
+	c.JSON(http.StatusOK, hook)
+}
+
+func (h *Handler) DeleteWebhook(c *gin.Context) {
+	id, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+	if err := h.service.DeleteWebhook(id); err != nil {
+		respondWebhookError(c, "Failed to delete webhook", err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// TestWebhook sends a signed ping to the subscription immediately and
+// returns the receiver's response.
+func (h *Handler) TestWebhook(c *gin.Context) {
+	id, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+	result, err := h.service.TestWebhook(c.Request.Context(), id)
+	if err != nil {
+		respondWebhookError(c, "Failed to test webhook", err)
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+// ListWebhookDeliveries shows the outbox. Query parameters: subscription_id,
+// status ("pending", "delivered" or "dead" for the dead-letter view) and limit.
+func (h *Handler) ListWebhookDeliveries(c *gin.Context) {
+	var subscriptionID uint
+	if v := c.Query("subscription_id"); v != "" {
+		id, err := strconv.ParseUint(v, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid subscription_id"})
+			return
+		}
+		subscriptionID = uint(id)
+	}
+	limit, _ := strconv.Atoi(c.Query("limit"))
+
+	deliveries, err := h.service.ListWebhookDeliveries(subscriptionID, c.Query("status"), limit)
+	if err != nil {
+		respondWebhookError(c, "Failed to fetch deliveries", err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"deliveries": deliveries})
+}
+
+// RetryWebhookDelivery requeues a delivery, typically one from the dead-letter view.
+func (h *Handler) RetryWebhookDelivery(c *gin.Context) {
+	id, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+	delivery, err := h.service.RetryWebhookDelivery(id)
+	if err != nil {
+		respondWebhookError(c, "Failed to retry delivery", err)
+		return
+	}
+	c.JSON(http.StatusOK, delivery)
+}
@@ -0,0 +1,267 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	"gorm.io/gorm"
+
+	"github.com/alexbeattie/golangone/models"
+	"github.com/alexbeattie/golangone/stream"
+)
+
+const (
+	wsBuffer     = 64
+	wsWriteWait  = 10 * time.Second
+	wsPongWait   = 60 * time.Second
+	wsPingPeriod = wsPongWait * 9 / 10
+	wsMaxMessage = 4096
+)
+
+// wsRequest is a client message on the device socket.
+type wsRequest struct {
+	Action    string   `json:"action"` // "subscribe" or "unsubscribe"
+	All       bool     `json:"all"`
+	DeviceIDs []string `json:"device_ids"`
+	GroupIDs  []string `json:"group_ids"`
+}
+
+// wsMessage is a server message on the device socket.
+type wsMessage struct {
+	Type      string        `json:"type"` // "update", "subscriptions" or "error"
+	Event     *stream.Event `json:"event,omitempty"`
+	All       bool          `json:"all,omitempty"`
+	DeviceIDs []string      `json:"device_ids,omitempty"`
+	GroupIDs  []string      `json:"group_ids,omitempty"`
+	Error     string        `json:"error,omitempty"`
+}
+
+// wsFilter tracks what one socket is subscribed to. Devices hidden in the
+// user's preferences are never delivered, even when subscribed explicitly.
+type wsFilter struct {
+	mu      sync.Mutex
+	all     bool
+	devices map[string]bool
+	groups  map[string]bool
+	hidden  map[string]bool
+}
+
+func newWSFilter(hidden []string) *wsFilter {
+	f := &wsFilter{devices: map[string]bool{}, groups: map[string]bool{}}
+	f.setHidden(hidden)
+	return f
+}
+
+func (f *wsFilter) setHidden(hidden []string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.hidden = make(map[string]bool, len(hidden))
+	for _, id := range hidden {
+		f.hidden[id] = true
+	}
+}
+
+func (f *wsFilter) apply(req wsRequest) wsMessage {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	on := req.Action == "subscribe"
+	if req.All || (!on && len(req.DeviceIDs) == 0 && len(req.GroupIDs) == 0) {
+		f.all = on
+	}
+	for _, id := range req.DeviceIDs {
+		if on {
+			f.devices[id] = true
+		} else {
+			delete(f.devices, id)
+		}
+	}
+	for _, id := range req.GroupIDs {
+		if on {
+			f.groups[id] = true
+		} else {
+			delete(f.groups, id)
+		}
+	}
+
+	msg := wsMessage{Type: "subscriptions", All: f.all, DeviceIDs: []string{}, GroupIDs: []string{}}
+	for id := range f.devices {
+		msg.DeviceIDs = append(msg.DeviceIDs, id)
+	}
+	for id := range f.groups {
+		msg.GroupIDs = append(msg.GroupIDs, id)
+	}
+	return msg
+}
+
+func (f *wsFilter) match(d stream.DeviceDiff) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.hidden[d.DeviceID] {
+		return false
+	}
+	if f.all || f.devices[d.DeviceID] {
+		return true
+	}
+	for _, g := range d.GroupIDs {
+		if f.groups[g] {
+			return true
+		}
+	}
+	return false
+}
+
+func (h *Handler) upgrader() *websocket.Upgrader {
+	allowed := h.service.Config().AllowedOrigins
+	return &websocket.Upgrader{
+		CheckOrigin: func(r *http.Request) bool {
+			origin := r.Header.Get("Origin")
+			if origin == "" {
+				return true
+			}
+			for _, o := range allowed {
+				if o == origin {
+					return true
+				}
+			}
+			return false
+		},
+	}
+}
+
+// userPreferences loads the stored preferences of userID, or nil if there
+// are none.
+func (h *Handler) userPreferences(userID string) (*models.UserPreferences, error) {
+	if userID == "" {
+		return nil, nil
+	}
+	var prefs models.UserPreferences
+	err := h.db.Where("user_id = ?", userID).First(&prefs).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &prefs, nil
+}
+
+// hiddenDevices loads the HiddenDevices preference of userID, if any.
+func (h *Handler) hiddenDevices(userID string) ([]string, error) {
+	prefs, err := h.userPreferences(userID)
+	if prefs == nil || err != nil {
+		return nil, err
+	}
+	return prefs.HiddenDevices, nil
+}
+
+// DeviceSocket upgrades to a WebSocket on which the client subscribes to
+// devices or device groups and receives their change events:
+//
+//	{"action": "subscribe", "device_ids": ["..."], "group_ids": ["..."]}
+//	{"action": "unsubscribe", "device_ids": ["..."]}
+//	{"action": "subscribe", "all": true}
+//
+// The user_id query parameter selects whose HiddenDevices preference is
+// applied. A client that cannot keep up is disconnected with close code 1013
+// and should reconnect and refetch /api/v1/devices.
+func (h *Handler) DeviceSocket(c *gin.Context) {
+	userID := c.Query("user_id")
+	hidden, err := h.hiddenDevices(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch preferences"})
+		return
+	}
+
+	conn, err := h.upgrader().Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	sub, _, _ := h.service.DeviceHub().Subscribe("", wsBuffer)
+	defer sub.Close()
+
+	filter := newWSFilter(hidden)
+	replies := make(chan wsMessage, 8)
+	done := make(chan struct{})
+	quit := make(chan struct{})
+	defer close(quit)
+
+	go func() {
+		defer close(done)
+		conn.SetReadLimit(wsMaxMessage)
+		conn.SetReadDeadline(time.Now().Add(wsPongWait))
+		conn.SetPongHandler(func(string) error {
+			return conn.SetReadDeadline(time.Now().Add(wsPongWait))
+		})
+
+		for {
+			var req wsRequest
+			if err := conn.ReadJSON(&req); err != nil {
+				return
+			}
+
+			var reply wsMessage
+			switch req.Action {
+			case "subscribe", "unsubscribe":
+				// Pick up preference changes made since the socket opened.
+				if hidden, err := h.hiddenDevices(userID); err == nil {
+					filter.setHidden(hidden)
+				}
+				reply = filter.apply(req)
+			default:
+				reply = wsMessage{Type: "error", Error: "unknown action"}
+			}
+
+			select {
+			case replies <- reply:
+			case <-quit:
+				return
+			}
+		}
+	}()
+
+	ping := time.NewTicker(wsPingPeriod)
+	defer ping.Stop()
+
+	write := func(msg wsMessage) error {
+		conn.SetWriteDeadline(time.Now().Add(wsWriteWait))
+		return conn.WriteJSON(msg)
+	}
+
+	for {
+		select {
+		case <-done:
+			return
+		case reply := <-replies:
+			if write(reply) != nil {
+				return
+			}
+		case ev, ok := <-sub.C:
+			if !ok {
+				conn.SetWriteDeadline(time.Now().Add(wsWriteWait))
+				conn.WriteMessage(websocket.CloseMessage,
+					websocket.FormatCloseMessage(websocket.CloseTryAgainLater, "client too slow"))
+				return
+			}
+			if !filter.match(ev.Diff) {
+				continue
+			}
+			if write(wsMessage{Type: "update", Event: &ev}) != nil {
+				return
+			}
+		case <-ping.C:
+			if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(wsWriteWait)); err != nil {
+				log.Printf("WebSocket ping failed: %v", err)
+				return
+			}
+		}
+	}
+}
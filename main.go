@@ -2,6 +2,7 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
@@ -10,7 +11,9 @@ import (
 	"gorm.io/gorm"
 	"log"
 	"os"
-"time"
+	"strconv"
+	"time"
+
 	"github.com/alexbeattie/golangone/config"
 	"github.com/alexbeattie/golangone/handlers"
 	"github.com/alexbeattie/golangone/models"
@@ -23,22 +26,122 @@ func initDB(dsn string) (*gorm.DB, error) {
 		return nil, fmt.Errorf("failed to connect to database: %w", err)
 	}
 
-	if err := db.AutoMigrate(&models.UserPreferences{}); err != nil {
+	if err := db.AutoMigrate(
+		&models.UserPreferences{},
+		&models.StoredDevicePoint{},
+		&models.DeviceRecord{},
+		&models.Geofence{},
+		&models.Event{},
+		&models.AlertRule{},
+		&models.Alert{},
+		&models.SafetyScore{},
+		&models.DeviceDTC{},
+		&models.WebhookSubscription{},
+		&models.WebhookDelivery{},
+		&models.PendingAlertEmail{},
+		&models.GeocodeCacheEntry{},
+		&models.ReportSchedule{},
+		&models.ReportRun{},
+		&models.FuelOverride{},
+	); err != nil {
 		return nil, fmt.Errorf("failed to run migrations: %w", err)
 	}
 
 	return db, nil
 }
 
+// getEnv returns the value of key, or fallback when it is unset or empty.
+func getEnv(key, fallback string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return fallback
+}
+
+// getEnvInt parses key as an integer, falling back when unset or invalid.
+func getEnvInt(key string, fallback int) int {
+	v := os.Getenv(key)
+	if v == "" {
+		return fallback
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		log.Printf("Invalid %s %q, using %d: %v", key, v, fallback, err)
+		return fallback
+	}
+	return n
+}
+
+// getEnvFloat parses key as a float, falling back when unset or invalid.
+func getEnvFloat(key string, fallback float64) float64 {
+	v := os.Getenv(key)
+	if v == "" {
+		return fallback
+	}
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		log.Printf("Invalid %s %q, using %g: %v", key, v, fallback, err)
+		return fallback
+	}
+	return f
+}
+
+// getEnvDuration parses key as a time.Duration, falling back when unset or invalid.
+func getEnvDuration(key string, fallback time.Duration) time.Duration {
+	v := os.Getenv(key)
+	if v == "" {
+		return fallback
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		log.Printf("Invalid %s %q, using %s: %v", key, v, fallback, err)
+		return fallback
+	}
+	return d
+}
+
 func main() {
 	if err := godotenv.Load(); err != nil {
 		log.Fatalf("Error loading .env file: %v", err)
 	}
 
 	cfg := &config.Config{
-		OneStepGPSAPIKey: os.Getenv("ONESTEPGPS_API_KEY"),
-		GoogleMapsAPIKey: os.Getenv("GOOGLE_MAPS_API_KEY"),
-		DSN:              os.Getenv("DSN"),
+		OneStepGPSAPIKey:  os.Getenv("ONESTEPGPS_API_KEY"),
+		OneStepGPSBaseURL: getEnv("ONESTEPGPS_BASE_URL", config.DefaultOneStepGPSBaseURL),
+		OneStepGPSFixture: os.Getenv("ONESTEPGPS_FIXTURE"),
+		GoogleMapsAPIKey:  os.Getenv("GOOGLE_MAPS_API_KEY"),
+		DSN:               os.Getenv("DSN"),
+		AllowedOrigins: []string{
+			"http://localhost:8080",
+			"http://localhost:8081",
+			"http://192.168.68.66:8081",
+			"http://192.168.1.82:8081",
+		},
+
+		UpstreamMaxRetries:      getEnvInt("UPSTREAM_MAX_RETRIES", 2),
+		UpstreamRetryBaseDelay:  getEnvDuration("UPSTREAM_RETRY_BASE_DELAY", 200*time.Millisecond),
+		UpstreamRetryMaxDelay:   getEnvDuration("UPSTREAM_RETRY_MAX_DELAY", 5*time.Second),
+		BreakerFailureThreshold: getEnvInt("UPSTREAM_BREAKER_THRESHOLD", 5),
+		BreakerCooldown:         getEnvDuration("UPSTREAM_BREAKER_COOLDOWN", 30*time.Second),
+		DeviceCacheTTL:          getEnvDuration("DEVICE_CACHE_TTL", 15*time.Second),
+		DeviceCacheMaxStale:     getEnvDuration("DEVICE_CACHE_MAX_STALE", 5*time.Minute),
+		IngestInterval:          getEnvDuration("INGEST_INTERVAL", 30*time.Second),
+		StreamBacklog:           getEnvInt("STREAM_BACKLOG", 1000),
+		GeofenceMargin:          getEnvFloat("GEOFENCE_MARGIN_METERS", 20),
+		GeofenceDwell:           getEnvDuration("GEOFENCE_DWELL", 10*time.Minute),
+		SMTPHost:                os.Getenv("SMTP_HOST"),
+		SMTPPort:                getEnvInt("SMTP_PORT", 587),
+		SMTPUsername:            os.Getenv("SMTP_USERNAME"),
+		SMTPPassword:            os.Getenv("SMTP_PASSWORD"),
+		SMTPFrom:                getEnv("SMTP_FROM", "alerts@localhost"),
+		EmailDigestInterval:     getEnvDuration("EMAIL_DIGEST_INTERVAL", time.Hour),
+		EmailBurstLimit:         getEnvInt("EMAIL_BURST_LIMIT", 5),
+		Geocoder:                os.Getenv("GEOCODER"),
+		GeocoderDataset:         os.Getenv("GEOCODER_DATASET"),
+		GeocodeMaxDistance:      getEnvFloat("GEOCODE_MAX_DISTANCE_METERS", 50000),
+		GeocodeRateLimit:        getEnvFloat("GEOCODE_RATE_LIMIT", 10),
+		GeocodePrecision:        getEnvInt("GEOCODE_PRECISION", 4),
+		GeocodeCacheTTL:         getEnvDuration("GEOCODE_CACHE_TTL", 30*24*time.Hour),
 	}
 
 	db, err := initDB(cfg.DSN)
@@ -49,16 +152,23 @@ func main() {
 	service := services.NewService(db, cfg)
 	handler := handlers.NewHandler(service, db)
 
+	ingestor := services.NewIngestor(service, cfg.IngestInterval,
+		service.DeviceHub(),
+		services.NewGeofenceMonitor(service, cfg.GeofenceMargin, cfg.GeofenceDwell),
+		services.NewAlertEngine(service),
+		services.NewHarshEventDetector(service),
+		services.NewDTCTracker(service),
+	)
+	go ingestor.Run(context.Background())
+	go service.RunWeeklyScorecards(context.Background())
+	go service.RunWebhookDispatcher(context.Background())
+	go service.RunEmailNotifier(context.Background())
+	go service.RunReportScheduler(context.Background())
+
 	r := gin.Default()
 	// Add CORS middleware
 	r.Use(cors.New(cors.Config{
-		AllowOrigins: []string{
-				"http://localhost:8080",
-        "http://localhost:8081",  // This is already there
-        "http://192.168.68.66:8081",  // Add this new IP address
-        "http://192.168.1.82:8081",
-
-		},
+		AllowOrigins: cfg.AllowedOrigins,
 		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
 		AllowHeaders:     []string{  "Origin",
         "Content-Type",
@@ -76,6 +186,59 @@ func main() {
     api.GET("/preferences/:userId", handler.GetUserPreferences)    // Changed from GetPreferences
     api.PUT("/preferences/:userId", handler.UpdateUserPreferences) // Changed from UpdatePreferences
     api.GET("/devices", handler.GetDevices)
+    api.GET("/devices/:deviceId/history", handler.GetDeviceHistory)
+    api.GET("/devices/:deviceId/drive-stop", handler.GetLocalDriveStops)
+    api.GET("/devices/:deviceId/dtcs", handler.GetDeviceDTCs)
+    api.GET("/devices/:deviceId/track.gpx", handler.GetTrackGPX)
+    api.GET("/devices/:deviceId/track.kml", handler.GetTrackKML)
+    api.GET("/devices/:deviceId/fuel-settings", handler.GetFuelSettings)
+    api.PUT("/devices/:deviceId/fuel-settings", handler.SetFuelOverride)
+    api.DELETE("/devices/:deviceId/fuel-settings", handler.DeleteFuelOverride)
+    api.GET("/health", handler.GetHealth)
+    api.GET("/geocode/reverse", handler.ReverseGeocode)
+    api.GET("/stream/devices", handler.StreamDevices)
+    api.GET("/stream/ws", handler.DeviceSocket)
+
+    api.GET("/geofences", handler.ListGeofences)
+    api.POST("/geofences", handler.CreateGeofence)
+    api.GET("/geofences/:id", handler.GetGeofence)
+    api.PUT("/geofences/:id", handler.UpdateGeofence)
+    api.DELETE("/geofences/:id", handler.DeleteGeofence)
+    api.GET("/geofences/:id/devices", handler.GetGeofenceDevices)
+
+    api.GET("/events", handler.ListEvents)
+
+    api.GET("/users/:userId/alert-rules", handler.ListAlertRules)
+    api.POST("/users/:userId/alert-rules", handler.CreateAlertRule)
+    api.GET("/users/:userId/alert-rules/:id", handler.GetAlertRule)
+    api.PUT("/users/:userId/alert-rules/:id", handler.UpdateAlertRule)
+    api.DELETE("/users/:userId/alert-rules/:id", handler.DeleteAlertRule)
+    api.GET("/users/:userId/alerts", handler.ListAlerts)
+    api.POST("/users/:userId/alerts/:id/acknowledge", handler.AcknowledgeAlert)
+    api.POST("/users/:userId/alerts/:id/resolve", handler.ResolveAlert)
+    api.GET("/users/:userId/report-schedules", handler.ListReportSchedules)
+    api.POST("/users/:userId/report-schedules", handler.CreateReportSchedule)
+    api.GET("/users/:userId/report-schedules/:id", handler.GetReportSchedule)
+    api.PUT("/users/:userId/report-schedules/:id", handler.UpdateReportSchedule)
+    api.DELETE("/users/:userId/report-schedules/:id", handler.DeleteReportSchedule)
+    api.POST("/users/:userId/report-schedules/:id/run", handler.RunReportSchedule)
+    api.GET("/users/:userId/report-runs", handler.ListReportRuns)
+
+    api.GET("/reports/speeding", handler.GetSpeedingReport)
+    api.GET("/reports/scorecards", handler.GetScorecards)
+    api.GET("/reports/trips.csv", handler.GetTripsCSV)
+    api.GET("/reports/trips.xlsx", handler.GetTripsXLSX)
+    api.GET("/reports/fleet-summary.pdf", handler.GetFleetSummaryPDF)
+    api.GET("/reports/fuel", handler.GetFuelReport)
+
+    api.GET("/webhooks", handler.ListWebhooks)
+    api.POST("/webhooks", handler.CreateWebhook)
+    api.GET("/webhooks/:id", handler.GetWebhook)
+    api.PUT("/webhooks/:id", handler.UpdateWebhook)
+    api.DELETE("/webhooks/:id", handler.DeleteWebhook)
+    api.POST("/webhooks/:id/test", handler.TestWebhook)
+    api.GET("/webhook-deliveries", handler.ListWebhookDeliveries)
+    api.POST("/webhook-deliveries/:id/retry", handler.RetryWebhookDelivery)
 }
 	// Add this new v3 group
 	v3 := r.Group("/v3/api")
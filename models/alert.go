@@ -0,0 +1,75 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Alert rule types.
+const (
+	RuleSpeed      = "speed"       // speed above Threshold (Unit "mph" or "km/h")
+	RuleLowVoltage = "low_voltage" // external voltage below Threshold volts
+	RuleOffline    = "offline"     // offline longer than DurationSeconds, or the device's offline_timeout
+	RuleAfterHours = "after_hours" // ignition on outside business hours
+	RuleDTC        = "dtc"         // a new diagnostic trouble code is reported
+)
+
+// Alert severities.
+const (
+	SeverityInfo     = "info"
+	SeverityWarning  = "warning"
+	SeverityCritical = "critical"
+)
+
+// Alert states.
+const (
+	AlertOpen         = "open"
+	AlertAcknowledged = "acknowledged"
+	AlertResolved     = "resolved"
+)
+
+// AlertRule is a user-defined condition evaluated against incoming points.
+// An empty DeviceIDs list applies the rule to every device.
+type AlertRule struct {
+	gorm.Model
+	UserID    string   `json:"user_id" gorm:"not null;index"`
+	Name      string   `json:"name"`
+	Type      string   `json:"type" gorm:"not null"`
+	DeviceIDs []string `json:"device_ids" gorm:"serializer:json"`
+	Severity  string   `json:"severity"`
+	Enabled   bool     `json:"enabled"`
+
+	Threshold       float64 `json:"threshold,omitempty"`
+	Unit            string  `json:"unit,omitempty"`
+	DurationSeconds int     `json:"duration_seconds,omitempty"`
+
+	// Business hours for after_hours rules, as "15:04" local times in Timezone.
+	// BusinessDays uses time.Weekday numbering (0 = Sunday).
+	BusinessHoursStart string `json:"business_hours_start,omitempty"`
+	BusinessHoursEnd   string `json:"business_hours_end,omitempty"`
+	BusinessDays       []int  `json:"business_days,omitempty" gorm:"serializer:json"`
+	Timezone           string `json:"timezone,omitempty"`
+}
+
+// Alert is raised when a rule's condition becomes true for a device and stays
+// open until acknowledged and resolved, either by a user or automatically
+// when the condition clears.
+type Alert struct {
+	gorm.Model
+	RuleID         *uint      `json:"rule_id,omitempty" gorm:"index"`
+	UserID         string     `json:"user_id" gorm:"not null;index"`
+	DeviceID       string     `json:"device_id" gorm:"not null;index"`
+	Type           string     `json:"type"`
+	Severity       string     `json:"severity"`
+	Status         string     `json:"status" gorm:"not null;index"`
+	DeviceDTCID    *uint      `json:"device_dtc_id,omitempty" gorm:"index"` // set on dtc alerts
+	Message        string     `json:"message"`
+	Value          float64    `json:"value"`
+	Lat            float64    `json:"lat"`
+	Lng            float64    `json:"lng"`
+	TriggeredAt    time.Time  `json:"triggered_at"`
+	AcknowledgedAt *time.Time `json:"acknowledged_at,omitempty"`
+	AcknowledgedBy string     `json:"acknowledged_by,omitempty"`
+	ResolvedAt     *time.Time `json:"resolved_at,omitempty"`
+}
@@ -0,0 +1,13 @@
+package models
+
+import "time"
+
+// DeviceRecord is the locally stored copy of a device's identity and settings,
+// refreshed by the ingestion worker so stored history can be interpreted
+// without the upstream.
+type DeviceRecord struct {
+	DeviceID    string                 `json:"device_id" gorm:"primaryKey"`
+	DisplayName string                 `json:"display_name"`
+	Settings    map[string]interface{} `json:"settings" gorm:"serializer:json"`
+	UpdatedAt   time.Time              `json:"updated_at"`
+}
@@ -0,0 +1,153 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/alexbeattie/golangone/geo"
+)
+
+const (
+	metersPerMi = geo.KmPerMile * metersPerKm
+	metersPerFt = 0.3048
+	metersPerKm = 1000
+)
+
+// unset reports whether m is missing. A zero Measurement counts as missing:
+// it is what decoding leaves behind for an empty or mistyped object.
+func (m *Measurement) unset() bool {
+	return m == nil || *m == Measurement{}
+}
+
+// Kph returns a speed in km/h. Speeds without a unit are taken as km/h.
+func (m *Measurement) Kph() (float64, bool) {
+	if m.unset() {
+		return 0, false
+	}
+	if m.Unit == "mph" {
+		return m.Value * geo.KmPerMile, true
+	}
+	return m.Value, true
+}
+
+// Meters returns a distance in meters. Distances without a unit are taken as meters.
+func (m *Measurement) Meters() (float64, bool) {
+	if m.unset() {
+		return 0, false
+	}
+	switch m.Unit {
+	case "km":
+		return m.Value * metersPerKm, true
+	case "mi":
+		return m.Value * metersPerMi, true
+	case "ft":
+		return m.Value * metersPerFt, true
+	default:
+		return m.Value, true
+	}
+}
+
+// Duration returns a duration. Durations without a unit are taken as seconds.
+func (m *Measurement) Duration() (time.Duration, bool) {
+	if m.unset() {
+		return 0, false
+	}
+	switch m.Unit {
+	case "ms":
+		return time.Duration(m.Value * float64(time.Millisecond)), true
+	case "m", "min":
+		return time.Duration(m.Value * float64(time.Minute)), true
+	case "h":
+		return time.Duration(m.Value * float64(time.Hour)), true
+	default:
+		return time.Duration(m.Value * float64(time.Second)), true
+	}
+}
+
+// Fuel economy measurements.
+const (
+	FuelMPG          = "mpg"     // US miles per gallon
+	FuelKmPerLitre   = "km/l"    // kilometres per litre
+	FuelLitresPer100 = "l/100km" // litres per 100 km
+)
+
+// FuelConsumption is a device's settings.fuel_consumption. FuelEconomy is in
+// Measurement units and FuelCost is per gallon for mpg, otherwise per litre.
+type FuelConsumption struct {
+	CalculationMethod string  `json:"calculation_method"`
+	Measurement       string  `json:"measurement"`
+	FuelType          string  `json:"fuel_type"`
+	FuelCost          float64 `json:"fuel_cost"`
+	FuelEconomy       float64 `json:"fuel_economy"`
+}
+
+// KmPerLitre returns FuelEconomy in km/l, or false if it is unset or in an
+// unknown measurement.
+func (f *FuelConsumption) KmPerLitre() (float64, bool) {
+	if f == nil || f.FuelEconomy <= 0 {
+		return 0, false
+	}
+	switch f.Measurement {
+	case FuelMPG, "":
+		return f.FuelEconomy * geo.KmPerMile / geo.LitresPerGal, true
+	case FuelKmPerLitre:
+		return f.FuelEconomy, true
+	case FuelLitresPer100:
+		return 100 / f.FuelEconomy, true
+	}
+	return 0, false
+}
+
+// UnitLitres returns the size in litres of the volume FuelCost is priced in:
+// a US gallon for mpg, otherwise a litre.
+func (f *FuelConsumption) UnitLitres() float64 {
+	if f == nil || f.Measurement == FuelMPG || f.Measurement == "" {
+		return geo.LitresPerGal
+	}
+	return 1
+}
+
+// CostPerLitre returns FuelCost per litre.
+func (f *FuelConsumption) CostPerLitre() float64 {
+	if f == nil {
+		return 0
+	}
+	return f.FuelCost / f.UnitLitres()
+}
+
+// DeviceSettings is the typed form of a device's "settings" object. Only the
+// fields the server uses are modeled; Device.Settings keeps the raw object so
+// nothing is lost when devices are passed through.
+type DeviceSettings struct {
+	BeginMovingSpeed     *Measurement     `json:"begin_moving_speed,omitempty"`
+	BeginStoppedSpeed    *Measurement     `json:"begin_stopped_speed,omitempty"`
+	MaxDriftDistance     *Measurement     `json:"max_drift_distance,omitempty"`
+	MinNumSatellites     int              `json:"min_num_satellites,omitempty"`
+	MaxHDOP              float64          `json:"max_hdop,omitempty"`
+	DriveTimeout         *Measurement     `json:"drive_timeout,omitempty"`
+	StopTimeout          *Measurement     `json:"stop_timeout,omitempty"`
+	OfflineTimeout       *Measurement     `json:"offline_timeout,omitempty"`
+	HistoryCalcDuration  *Measurement     `json:"history_calc_duration,omitempty"`
+	HarshEventMinSpeed   *Measurement     `json:"harsh_event_min_speed,omitempty"`
+	HistoryRetentionDays int              `json:"history_retention_days,omitempty"`
+	VIN                  string           `json:"vin,omitempty"`
+	FuelConsumption      *FuelConsumption `json:"fuel_consumption,omitempty"`
+}
+
+// ParseDeviceSettings converts a raw settings object. Fields with an
+// unexpected shape are skipped rather than failing the whole object.
+func ParseDeviceSettings(raw map[string]interface{}) DeviceSettings {
+	var s DeviceSettings
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return s
+	}
+	// Unmarshal skips mistyped fields and decodes the rest, so a type error
+	// only means some fields are unset.
+	var typeErr *json.UnmarshalTypeError
+	if err := json.Unmarshal(data, &s); err != nil && !errors.As(err, &typeErr) {
+		return DeviceSettings{}
+	}
+	return s
+}
@@ -0,0 +1,81 @@
+package models
+
+import (
+	"encoding/json"
+	"log"
+	"time"
+)
+
+// EventTypeDTC events record diagnostic trouble codes appearing and clearing.
+const (
+	EventTypeDTC    = "dtc"
+	EventDTCNew     = "new"
+	EventDTCCleared = "cleared"
+)
+
+// DTC is one entry of DevicePointDetail.DtcList.
+type DTC struct {
+	Code string `json:"code"`
+}
+
+// DTCList accepts both objects ({"code": "P0420"}) and bare code strings. A
+// JSON null leaves Codes nil, meaning the point did not report codes; an
+// empty list means no codes are active. Like MotionLog it is re-encoded
+// exactly as received. Entries of any other shape are logged and skipped;
+// the list never fails decoding of the whole point.
+type DTCList struct {
+	Codes []DTC
+
+	raw json.RawMessage
+}
+
+func (l *DTCList) UnmarshalJSON(data []byte) error {
+	*l = DTCList{raw: append(json.RawMessage(nil), data...)}
+	var entries []json.RawMessage
+	if err := json.Unmarshal(data, &entries); err != nil {
+		if string(data) != "null" {
+			log.Printf("Ignoring dtc_list of unexpected shape: %.200s", data)
+		}
+		return nil
+	}
+	if entries == nil {
+		return nil
+	}
+
+	codes := make([]DTC, 0, len(entries))
+	for _, entry := range entries {
+		var code string
+		if err := json.Unmarshal(entry, &code); err == nil {
+			codes = append(codes, DTC{Code: code})
+			continue
+		}
+		var dtc DTC
+		if err := json.Unmarshal(entry, &dtc); err != nil || dtc.Code == "" {
+			log.Printf("Ignoring dtc_list entry of unexpected shape: %.200s", entry)
+			continue
+		}
+		codes = append(codes, dtc)
+	}
+	l.Codes = codes
+	return nil
+}
+
+func (l DTCList) MarshalJSON() ([]byte, error) {
+	if l.raw == nil {
+		return []byte("null"), nil
+	}
+	return l.raw, nil
+}
+
+// DeviceDTC is one occurrence of a trouble code on a device, from when it was
+// first reported until it stopped being reported.
+type DeviceDTC struct {
+	ID          uint       `json:"id" gorm:"primaryKey"`
+	DeviceID    string     `json:"device_id" gorm:"not null;index:idx_device_dtcs_lookup,priority:1"`
+	Code        string     `json:"code" gorm:"not null;index:idx_device_dtcs_lookup,priority:2"`
+	System      string     `json:"system"`
+	Description string     `json:"description"`
+	FirstSeen   time.Time  `json:"first_seen"`
+	LastSeen    time.Time  `json:"last_seen"`
+	ClearedAt   *time.Time `json:"cleared_at,omitempty"`
+}
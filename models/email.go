@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+// PendingAlertEmail queues an alert for email delivery to its rule's owner
+// until the email notifier sends it, individually or as part of a digest.
+// ClaimedUntil hides rows from other notifiers while one is sending them.
+type PendingAlertEmail struct {
+	ID           uint       `json:"id" gorm:"primaryKey"`
+	UserID       string     `json:"user_id" gorm:"not null;index"`
+	AlertID      uint       `json:"alert_id" gorm:"not null"`
+	CreatedAt    time.Time  `json:"created_at" gorm:"index"`
+	ClaimedUntil *time.Time `json:"claimed_until,omitempty"`
+}
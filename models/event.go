@@ -0,0 +1,30 @@
+package models
+
+import "time"
+
+// Event types.
+const (
+	EventTypeGeofence = "geofence"
+)
+
+// Geofence event subtypes.
+const (
+	EventEnter = "enter"
+	EventExit  = "exit"
+	EventDwell = "dwell"
+)
+
+// Event is something that happened to a device at a point in time, detected
+// by the server while ingesting points.
+type Event struct {
+	ID         uint                   `json:"id" gorm:"primaryKey"`
+	Type       string                 `json:"type" gorm:"not null;index:idx_events_lookup,priority:1"`
+	Subtype    string                 `json:"subtype"`
+	DeviceID   string                 `json:"device_id" gorm:"not null;index:idx_events_lookup,priority:2"`
+	GeofenceID *uint                  `json:"geofence_id,omitempty" gorm:"index"`
+	OccurredAt time.Time              `json:"occurred_at" gorm:"index:idx_events_lookup,priority:3"`
+	Lat        float64                `json:"lat"`
+	Lng        float64                `json:"lng"`
+	Data       map[string]interface{} `json:"data,omitempty" gorm:"serializer:json"`
+	CreatedAt  time.Time              `json:"created_at"`
+}
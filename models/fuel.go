@@ -0,0 +1,38 @@
+package models
+
+import "time"
+
+// FuelOverride replaces parts of a device's settings.fuel_consumption for
+// fuel reports, e.g. when the upstream economy is unset or a fleet pays a
+// negotiated price. Empty and nil fields fall back to the device settings.
+type FuelOverride struct {
+	DeviceID    string   `json:"device_id" gorm:"primaryKey"`
+	Measurement string   `json:"measurement,omitempty"`
+	FuelType    string   `json:"fuel_type,omitempty"`
+	FuelEconomy *float64 `json:"fuel_economy,omitempty"`
+	FuelCost    *float64 `json:"fuel_cost,omitempty"`
+	// IdleRate is fuel burned per hour of idling, in gallons for mpg and
+	// litres otherwise.
+	IdleRate  *float64  `json:"idle_rate,omitempty"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Apply returns fc with the override's fields replacing its own.
+func (o *FuelOverride) Apply(fc FuelConsumption) FuelConsumption {
+	if o == nil {
+		return fc
+	}
+	if o.Measurement != "" {
+		fc.Measurement = o.Measurement
+	}
+	if o.FuelType != "" {
+		fc.FuelType = o.FuelType
+	}
+	if o.FuelEconomy != nil {
+		fc.FuelEconomy = *o.FuelEconomy
+	}
+	if o.FuelCost != nil {
+		fc.FuelCost = *o.FuelCost
+	}
+	return fc
+}
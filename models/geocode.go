@@ -0,0 +1,24 @@
+package models
+
+import "time"
+
+// GeocodeCacheEntry is a stored reverse geocoding result for a coordinate
+// rounded to the configured precision. An empty Formatted address records
+// that the provider had no result, so the location isn't queried again.
+// Entries are per provider, so switching providers doesn't overwrite them.
+type GeocodeCacheEntry struct {
+	Key         string    `json:"key" gorm:"column:cache_key;primaryKey"`
+	Lat         float64   `json:"lat"`
+	Lng         float64   `json:"lng"`
+	Provider    string    `json:"provider" gorm:"primaryKey"`
+	Formatted   string    `json:"formatted"`
+	City        string    `json:"city"`
+	State       string    `json:"state"`
+	Country     string    `json:"country"`
+	CountryCode string    `json:"country_code"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+func (GeocodeCacheEntry) TableName() string {
+	return "geocode_cache"
+}
@@ -0,0 +1,31 @@
+package models
+
+import (
+	"encoding/json"
+
+	"gorm.io/gorm"
+)
+
+// Geofence kinds.
+const (
+	GeofenceCircle  = "circle"
+	GeofencePolygon = "polygon"
+)
+
+// GeoJSONGeometry is a GeoJSON geometry object. Coordinates are kept raw and
+// decoded according to Type.
+type GeoJSONGeometry struct {
+	Type        string          `json:"type"`
+	Coordinates json.RawMessage `json:"coordinates"`
+}
+
+// Geofence is a named zone. A circle is a GeoJSON Point with RadiusMeters; a
+// polygon is a GeoJSON Polygon.
+type Geofence struct {
+	gorm.Model
+	Name         string          `json:"name" gorm:"not null"`
+	Description  string          `json:"description"`
+	Kind         string          `json:"kind" gorm:"not null"`
+	Geometry     GeoJSONGeometry `json:"geometry" gorm:"serializer:json"`
+	RadiusMeters float64         `json:"radius_meters,omitempty"`
+}
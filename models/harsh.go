@@ -0,0 +1,126 @@
+package models
+
+import (
+	"encoding/json"
+	"log"
+)
+
+// Harsh driving event categories, used as Event subtypes.
+const (
+	EventTypeHarsh    = "harsh"
+	HarshBraking      = "braking"
+	HarshAcceleration = "acceleration"
+	HarshCornering    = "cornering"
+	HarshOther        = "other"
+)
+
+const (
+	gravity    = 9.80665
+	milliGPerG = 1000.0
+)
+
+// HarshEvent is one entry of DevicePointDetail.HeventList.
+type HarshEvent struct {
+	HeventType string       `json:"hevent_type"`
+	Force      *Measurement `json:"force,omitempty"`
+}
+
+// HarshEventList accepts both the object form and a bare list of event type
+// strings, since devices report either. Like MotionLog it is re-encoded
+// exactly as received. Entries of any other shape are logged and dropped;
+// the list never fails decoding of the whole point.
+type HarshEventList struct {
+	Events []HarshEvent
+
+	raw json.RawMessage
+}
+
+func (l *HarshEventList) UnmarshalJSON(data []byte) error {
+	*l = HarshEventList{raw: append(json.RawMessage(nil), data...)}
+	var entries []json.RawMessage
+	if err := json.Unmarshal(data, &entries); err != nil {
+		if string(data) != "null" {
+			log.Printf("Ignoring hevent_list of unexpected shape: %.200s", data)
+		}
+		return nil
+	}
+
+	events := make([]HarshEvent, 0, len(entries))
+	for _, entry := range entries {
+		var name string
+		if err := json.Unmarshal(entry, &name); err == nil {
+			events = append(events, HarshEvent{HeventType: name})
+			continue
+		}
+		var event HarshEvent
+		if err := json.Unmarshal(entry, &event); err != nil {
+			log.Printf("Ignoring hevent_list entry of unexpected shape: %.200s", entry)
+			continue
+		}
+		events = append(events, event)
+	}
+	l.Events = events
+	return nil
+}
+
+func (l HarshEventList) MarshalJSON() ([]byte, error) {
+	if l.raw == nil {
+		return []byte("null"), nil
+	}
+	return l.raw, nil
+}
+
+// MotionLog summarises the forces measured since the previous point. It is
+// re-encoded exactly as received, so typing it does not change the device
+// JSON passed through to clients; a motion log of unexpected shape is logged
+// and reads as empty.
+type MotionLog struct {
+	StartTime            *string     `json:"start_time"`
+	EndTime              *string     `json:"end_time"`
+	StartHeading         *float64    `json:"start_heading"`
+	EndHeading           *float64    `json:"end_heading"`
+	MaxAcceleratingForce Measurement `json:"max_accelerating_force"`
+	MaxDeceleratingForce Measurement `json:"max_decelerating_force"`
+	MaxRightTurnForce    Measurement `json:"max_right_turn_force"`
+	MaxLeftTurnForce     Measurement `json:"max_left_turn_force"`
+
+	raw json.RawMessage
+}
+
+// motionLogFields decodes MotionLog without its custom methods.
+type motionLogFields MotionLog
+
+func (m *MotionLog) UnmarshalJSON(data []byte) error {
+	var fields motionLogFields
+	if err := json.Unmarshal(data, &fields); err != nil {
+		log.Printf("Ignoring motion_log of unexpected shape: %.200s", data)
+		*m = MotionLog{}
+		return nil
+	}
+	*m = MotionLog(fields)
+	m.raw = append(json.RawMessage(nil), data...)
+	return nil
+}
+
+func (m MotionLog) MarshalJSON() ([]byte, error) {
+	if m.raw == nil {
+		return []byte("null"), nil
+	}
+	return m.raw, nil
+}
+
+// ForceG returns a force measurement in g. Unitless values are taken as g
+// and a missing force as zero.
+func ForceG(m *Measurement) float64 {
+	if m == nil {
+		return 0
+	}
+	switch m.Unit {
+	case "mg", "mG", "milli_g":
+		return m.Value / milliGPerG
+	case "m/s2", "m/s^2", "m/s²":
+		return m.Value / gravity
+	default:
+		return m.Value
+	}
+}
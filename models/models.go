@@ -21,6 +21,12 @@ type UserPreferences struct {
     ShowBattery     bool      `json:"show_battery"`
     ShowSatellites  bool      `json:"show_satellites"`
     ShowLastUpdate  bool      `json:"show_last_update"`
+    // Email notification opt-in. With EmailDigest set, alerts are batched
+    // into one email per digest interval instead of sent as they happen.
+    Email            string `json:"email"`
+    EmailAlerts      bool   `json:"email_alerts"`
+    EmailDigest      bool   `json:"email_digest"`
+    EmailMinSeverity string `json:"email_min_severity"`
     LastUpdated     time.Time `json:"last_updated"`
 }
 
@@ -56,6 +62,8 @@ type Device struct {
 	DeviceGroupsIDList       interface{}            `json:"device_groups_id_list"`
 	DeviceFieldList          interface{}            `json:"device_field_list"`
 	DeviceUISettings         map[string]interface{} `json:"device_ui_settings"`
+	// Address is the reverse geocoded LatestDevicePoint, set only when requested.
+	Address                  string                 `json:"address,omitempty"`
 }
 
 // DevicePoint represents a GPS location point with additional metadata
@@ -95,9 +103,9 @@ type DevicePointDetail struct {
 	Hdop                    float64                `json:"hdop"`
 	NumSatellites          int                    `json:"num_satellites"`
 	RemoteAddr             string                 `json:"remote_addr"`
-	HeventList             interface{}            `json:"hevent_list"`
-	DtcList                interface{}            `json:"dtc_list"`
-	MotionLog              map[string]interface{} `json:"motion_log"`
+	HeventList             HarshEventList         `json:"hevent_list"`
+	DtcList                DTCList                `json:"dtc_list"`
+	MotionLog              MotionLog              `json:"motion_log"`
 	PacketSequenceID       string                 `json:"packet_sequence_id"`
 	Rssi                   float64                `json:"rssi"`
 	TripDistance           map[string]interface{} `json:"trip_distance"`
@@ -182,6 +190,10 @@ type DriveStopPoint struct {
         Unit    string  `json:"unit"`
         Display string  `json:"display"`
     } `json:"odometer_to"`
+    // Per-segment statistics. Only filled by the local drive-stop engine.
+    Distance     *DurationData `json:"distance,omitempty"`
+    AverageSpeed *DurationData `json:"average_speed,omitempty"`
+    TopSpeed     *DurationData `json:"top_speed,omitempty"`
 }
 
 type DriveStopResponse struct {
@@ -194,6 +206,9 @@ type DriveStopResponse struct {
     StopDuration    DurationData `json:"stop_duration"`
     TopSpeed        DurationData `json:"top_speed"`
     DriveStopList   []DriveStopPoint `json:"drive_stop_list"`
+    // PointList holds the raw points upstream returns with the route
+    // (return_points=true). The local engine leaves it empty.
+    PointList       []DevicePoint `json:"point_list,omitempty"`
 }
 type Measurement struct {
     Value   float64 `json:"value"`
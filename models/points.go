@@ -0,0 +1,35 @@
+package models
+
+import "time"
+
+// StoredDevicePoint is a DevicePoint persisted by the ingestion worker. A point
+// is unique per device by its upstream DevicePointID.
+type StoredDevicePoint struct {
+	ID            uint      `json:"id" gorm:"primaryKey"`
+	DeviceID      string    `json:"device_id" gorm:"not null;uniqueIndex:idx_device_points_point,priority:1;index:idx_device_points_time,priority:1"`
+	DevicePointID string    `json:"device_point_id" gorm:"not null;uniqueIndex:idx_device_points_point,priority:2"`
+	Sequence      string    `json:"sequence"`
+	DtTracker     time.Time `json:"dt_tracker" gorm:"index:idx_device_points_time,priority:2"`
+	DtServer      time.Time `json:"dt_server"`
+	Lat           float64   `json:"lat"`
+	Lng           float64   `json:"lng"`
+	Speed         float64   `json:"speed"` // km/h, as reported upstream
+	Angle         int       `json:"angle"`
+	DriveStatus   string    `json:"drive_status"`
+	Odometer      float64   `json:"odometer"`
+	OdometerUnit  string    `json:"odometer_unit"`
+	ExternalVolt  float64   `json:"external_volt"`
+	Hdop          float64   `json:"hdop"`
+	NumSatellites int       `json:"num_satellites"`
+	Acc           bool      `json:"acc"`
+
+	PostedSpeedLimit float64 `json:"posted_speed_limit"` // mph, 0 when unknown
+	MphOverPosted    float64 `json:"mph_over_posted"`
+	PctOverPosted    float64 `json:"pct_over_posted"`
+
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (StoredDevicePoint) TableName() string {
+	return "device_points"
+}
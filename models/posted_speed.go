@@ -0,0 +1,53 @@
+package models
+
+import (
+	"strconv"
+
+	"github.com/alexbeattie/golangone/geo"
+)
+
+// PostedSpeed is the typed form of the posted speed limit data the upstream
+// attaches to a point. Speeds are in mph.
+type PostedSpeed struct {
+	Checked  bool    `json:"checked"`
+	LimitMph float64 `json:"limit_mph"`
+	MphOver  float64 `json:"mph_over"`
+	PctOver  float64 `json:"pct_over"`
+	Source   string  `json:"source,omitempty"`
+}
+
+// PostedSpeed parses the posted speed fields spread over Params (where they
+// arrive as strings) and DevicePointExternal.
+func (p DevicePoint) PostedSpeed() PostedSpeed {
+	ps := PostedSpeed{
+		Checked: paramFloat(p.Params, "posted_spd_checked") > 0,
+		MphOver: paramFloat(p.Params, "mph_over_posted"),
+		PctOver: paramFloat(p.Params, "pct_over_posted"),
+	}
+	ps.Source, _ = p.Params["posted_spd_src"].(string)
+
+	if limit, ok := p.DevicePointExternal["posted_speed_limit"].(map[string]interface{}); ok {
+		value := paramFloat(limit, "value")
+		if unit, _ := limit["unit"].(string); unit == "km/h" {
+			value /= geo.KmPerMile
+		}
+		ps.LimitMph = value
+	}
+	if ps.LimitMph == 0 {
+		ps.LimitMph = paramFloat(p.Params, "posted_spd_raven")
+	}
+	return ps
+}
+
+// paramFloat reads a number that may be encoded as a JSON number or a string.
+func paramFloat(m map[string]interface{}, key string) float64 {
+	switch v := m[key].(type) {
+	case float64:
+		return v
+	case string:
+		f, _ := strconv.ParseFloat(v, 64)
+		return f
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,67 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Scheduled report kinds, named after the export endpoints that produce them.
+const (
+	ReportTripsCSV        = "trips.csv"
+	ReportTripsXLSX       = "trips.xlsx"
+	ReportFleetSummaryPDF = "fleet-summary.pdf"
+)
+
+// Schedule presets accepted in place of a cron expression. Each runs at
+// midnight in the schedule's timezone: daily, on Mondays, or on the 1st.
+const (
+	ScheduleDaily   = "daily"
+	ScheduleWeekly  = "weekly"
+	ScheduleMonthly = "monthly"
+)
+
+// Report run states.
+const (
+	ReportRunRunning   = "running"
+	ReportRunSucceeded = "succeeded"
+	ReportRunFailed    = "failed"
+)
+
+// ReportSchedule emails a report to Recipients every time Schedule fires.
+// Each run covers the period since the schedule's previous fire time.
+// Schedule is a preset or a five-field cron expression evaluated in Timezone.
+type ReportSchedule struct {
+	gorm.Model
+	UserID     string   `json:"user_id" gorm:"not null;index"`
+	Name       string   `json:"name"`
+	Report     string   `json:"report" gorm:"not null"`
+	DeviceID   string   `json:"device_id,omitempty"`
+	Schedule   string   `json:"schedule" gorm:"not null"`
+	Timezone   string   `json:"timezone"`
+	Recipients []string `json:"recipients" gorm:"serializer:json"`
+	Enabled    bool     `json:"enabled"`
+
+	// Report options, as for the export endpoints.
+	Units        string `json:"units"`
+	StopDuration string `json:"stop_duration"`
+
+	NextRunAt  *time.Time `json:"next_run_at,omitempty" gorm:"index"`
+	LastRunAt  *time.Time `json:"last_run_at,omitempty"`
+	LastStatus string     `json:"last_status,omitempty"`
+	LastError  string     `json:"last_error,omitempty"`
+}
+
+// ReportRun records one execution of a schedule.
+type ReportRun struct {
+	ID         uint       `json:"id" gorm:"primaryKey"`
+	ScheduleID uint       `json:"schedule_id" gorm:"not null;index"`
+	Status     string     `json:"status" gorm:"not null"`
+	Manual     bool       `json:"manual"`
+	PeriodFrom time.Time  `json:"period_from"`
+	PeriodTo   time.Time  `json:"period_to"`
+	Bytes      int        `json:"bytes,omitempty"`
+	Error      string     `json:"error,omitempty"`
+	StartedAt  time.Time  `json:"started_at"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+}
@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+// SafetyScore is a device's driving score for one week (Monday 00:00 UTC
+// onwards). Higher is safer; 100 means no weighted events.
+type SafetyScore struct {
+	ID                uint      `json:"-" gorm:"primaryKey"`
+	DeviceID          string    `json:"device_id" gorm:"not null;uniqueIndex:idx_safety_scores_week,priority:2"`
+	DisplayName       string    `json:"display_name"`
+	WeekStart         time.Time `json:"week_start" gorm:"not null;uniqueIndex:idx_safety_scores_week,priority:1"`
+	Score             float64   `json:"score"`
+	DistanceMiles     float64   `json:"distance_miles"`
+	HarshBraking      int       `json:"harsh_braking"`
+	HarshAcceleration int       `json:"harsh_acceleration"`
+	HarshCornering    int       `json:"harsh_cornering"`
+	SpeedingIncidents int       `json:"speeding_incidents"`
+	Rank              int       `json:"rank" gorm:"-"`
+	ComputedAt        time.Time `json:"computed_at"`
+}
@@ -0,0 +1,51 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Notification types published to webhooks. Event types (geofence, harsh,
+// dtc) are published under their own names.
+const (
+	NotifyAlert         = "alert"
+	NotifyAlertResolved = "alert.resolved"
+	NotifyPing          = "ping"
+	NotifyAll           = "*"
+)
+
+// Webhook delivery states.
+const (
+	DeliveryPending   = "pending"
+	DeliveryDelivered = "delivered"
+	DeliveryDead      = "dead"
+)
+
+// WebhookSubscription sends every notification whose type is listed in
+// EventTypes ("*" for all) to URL, signed with Secret.
+type WebhookSubscription struct {
+	gorm.Model
+	UserID     string   `json:"user_id" gorm:"index"`
+	URL        string   `json:"url" gorm:"not null"`
+	EventTypes []string `json:"event_types" gorm:"serializer:json"`
+	Secret     string   `json:"secret,omitempty"`
+	Enabled    bool     `json:"enabled"`
+}
+
+// WebhookDelivery is an outbox row: one payload for one subscription, retried
+// with backoff until delivered or moved to the dead-letter state.
+type WebhookDelivery struct {
+	ID             uint       `json:"id" gorm:"primaryKey"`
+	SubscriptionID uint       `json:"subscription_id" gorm:"not null;index"`
+	EventType      string     `json:"event_type"`
+	Payload        string     `json:"payload" gorm:"type:text"`
+	Status         string     `json:"status" gorm:"not null;index:idx_webhook_deliveries_due,priority:1"`
+	Attempts       int        `json:"attempts"`
+	NextAttemptAt  time.Time  `json:"next_attempt_at" gorm:"index:idx_webhook_deliveries_due,priority:2"`
+	LastStatusCode int        `json:"last_status_code,omitempty"`
+	LastError      string     `json:"last_error,omitempty"`
+	DeliveredAt    *time.Time `json:"delivered_at,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
@@ -0,0 +1,182 @@
+package reports
+
+import (
+	"time"
+
+	"github.com/alexbeattie/golangone/drivestop"
+	"github.com/alexbeattie/golangone/geo"
+	"github.com/alexbeattie/golangone/models"
+)
+
+// DefaultIdleLitresPerHour is the idle burn used when a device has no idle
+// rate override, about what a light-duty gasoline engine uses (0.5 gal/h).
+const DefaultIdleLitresPerHour = 1.9
+
+// FuelMethodEconomy is the fuel calculation method FuelRows implements:
+// distance driven over fuel economy, plus idle burn. Devices configured for
+// any other method, such as "fuel_sensor", are estimated the same way and
+// their rows flagged with UnsupportedMethod.
+const FuelMethodEconomy = "fuel_economy"
+
+// Report periods for FuelRows. PeriodAll reports the whole window as one row.
+const (
+	PeriodAll   = ""
+	PeriodDay   = "day"
+	PeriodWeek  = "week"
+	PeriodMonth = "month"
+)
+
+// FuelProfile is the fuel model a device is reported with: its fuel
+// consumption settings with any override applied, plus an idle burn rate in
+// the same volume unit as the fuel cost.
+type FuelProfile struct {
+	models.FuelConsumption
+	IdleRate float64 `json:"idle_rate"`
+}
+
+// NewFuelProfile applies override to a device's fuel consumption settings.
+func NewFuelProfile(fc *models.FuelConsumption, override *models.FuelOverride) FuelProfile {
+	var p FuelProfile
+	if fc != nil {
+		p.FuelConsumption = *fc
+	}
+	p.FuelConsumption = override.Apply(p.FuelConsumption)
+	p.IdleRate = DefaultIdleLitresPerHour / p.UnitLitres()
+	if override != nil && override.IdleRate != nil {
+		p.IdleRate = *override.IdleRate
+	}
+	return p
+}
+
+// FuelRow is one device's estimated fuel use over one period. Distance is in
+// miles and fuel in US gallons when the report is imperial, km and litres
+// otherwise. Cost is in the currency of the fuel cost setting. Drive fuel is
+// zero and EconomyUnknown set when the device has no fuel economy.
+type FuelRow struct {
+	DeviceID       string        `json:"device_id"`
+	Name           string        `json:"name"`
+	PeriodStart    time.Time     `json:"period_start"`
+	PeriodEnd      time.Time     `json:"period_end"`
+	FuelType       string        `json:"fuel_type,omitempty"`
+	Distance       float64       `json:"distance"`
+	IdleTime       time.Duration `json:"idle_time"`
+	DriveFuel      float64       `json:"drive_fuel"`
+	IdleFuel       float64       `json:"idle_fuel"`
+	Fuel           float64       `json:"fuel"`
+	Cost           float64       `json:"cost"`
+	EconomyUnknown bool          `json:"economy_unknown,omitempty"`
+
+	// UnsupportedMethod names the device's calculation method when it isn't
+	// FuelMethodEconomy: the row is then an estimate the device didn't ask for.
+	UnsupportedMethod string `json:"unsupported_method,omitempty"`
+}
+
+// PeriodStart returns the start of the period containing t, in loc. Weeks
+// start on Monday.
+func PeriodStart(t time.Time, period string, loc *time.Location) time.Time {
+	t = t.In(loc)
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
+	switch period {
+	case PeriodDay:
+		return day
+	case PeriodWeek:
+		return day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+	case PeriodMonth:
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, loc)
+	}
+	return t
+}
+
+func periodEnd(start time.Time, period string) time.Time {
+	switch period {
+	case PeriodDay:
+		return start.AddDate(0, 0, 1)
+	case PeriodWeek:
+		return start.AddDate(0, 0, 7)
+	case PeriodMonth:
+		return start.AddDate(0, 1, 0)
+	}
+	return start
+}
+
+// FuelRows estimates a device's fuel use over [from, to] from the distance it
+// drove and the time it idled. Segments are counted in the period they start
+// in; with PeriodAll there is a single row, otherwise one per period with
+// activity, clipped to the window.
+func FuelRows(t DeviceTrips, profile FuelProfile, from, to time.Time, period string, loc *time.Location, imperial bool) []FuelRow {
+	newRow := func(start, end time.Time) *FuelRow {
+		if start.Before(from) {
+			start = from
+		}
+		if end.After(to) {
+			end = to
+		}
+		return &FuelRow{DeviceID: t.DeviceID, Name: t.Name, FuelType: profile.FuelType, PeriodStart: start, PeriodEnd: end}
+	}
+	var unsupported string
+	if m := profile.CalculationMethod; m != "" && m != FuelMethodEconomy {
+		unsupported = m
+	}
+
+	var rows []*FuelRow
+	byStart := map[time.Time]*FuelRow{}
+	if period == PeriodAll {
+		row := newRow(from, to)
+		rows = append(rows, row)
+		byStart[time.Time{}] = row
+	}
+
+	for _, seg := range t.Route.DriveStopList {
+		if seg.Type != drivestop.TypeDrive && seg.Type != drivestop.TypeIdle {
+			continue
+		}
+
+		var key time.Time
+		if period != PeriodAll {
+			start, err := time.Parse(time.RFC3339, seg.TimeFrom)
+			if err != nil {
+				continue
+			}
+			key = PeriodStart(start, period, loc)
+		}
+		row, ok := byStart[key]
+		if !ok {
+			row = newRow(key, periodEnd(key, period))
+			rows = append(rows, row)
+			byStart[key] = row
+		}
+
+		switch {
+		case seg.Type == drivestop.TypeDrive && seg.Distance != nil:
+			row.Distance += convertDistance(seg.Distance.Value, seg.Distance.Unit, imperial)
+		case seg.Type == drivestop.TypeIdle:
+			row.IdleTime += time.Duration(seg.Duration.Value) * time.Second
+		}
+	}
+
+	kmPerLitre, known := profile.KmPerLitre()
+	out := make([]FuelRow, len(rows))
+	for i, row := range rows {
+		km := row.Distance
+		if imperial {
+			km *= geo.KmPerMile
+		}
+
+		var driveL float64
+		if known {
+			driveL = km / kmPerLitre
+		}
+		idleL := row.IdleTime.Hours() * profile.IdleRate * profile.UnitLitres()
+
+		row.Cost = (driveL + idleL) * profile.CostPerLitre()
+		row.EconomyUnknown = !known
+		row.UnsupportedMethod = unsupported
+		row.DriveFuel, row.IdleFuel = driveL, idleL
+		if imperial {
+			row.DriveFuel, row.IdleFuel = driveL/geo.LitresPerGal, idleL/geo.LitresPerGal
+		}
+		row.Fuel = row.DriveFuel + row.IdleFuel
+		out[i] = *row
+	}
+	return out
+}
@@ -0,0 +1,28 @@
+package reports
+
+import "math"
+
+// Weights of each event kind in the safety score.
+const (
+	weightBraking      = 3
+	weightAcceleration = 2
+	weightCornering    = 2
+	weightSpeeding     = 4
+
+	// minScoreMiles keeps a handful of events over a very short distance
+	// from sinking a score to zero.
+	minScoreMiles = 10
+	// pointsPerWeightedEvent is deducted per weighted event per 100 miles.
+	pointsPerWeightedEvent = 2
+)
+
+// SafetyScore rates driving from 0 to 100: weighted events are normalised per
+// 100 miles driven and deducted from a perfect score.
+func SafetyScore(miles float64, braking, acceleration, cornering, speeding int) float64 {
+	weighted := float64(weightBraking*braking + weightAcceleration*acceleration +
+		weightCornering*cornering + weightSpeeding*speeding)
+	per100 := weighted / math.Max(miles, minScoreMiles) * 100
+
+	score := 100 - per100*pointsPerWeightedEvent
+	return math.Round(math.Max(0, math.Min(100, score))*10) / 10
+}
@@ -0,0 +1,78 @@
+// Package reports derives fleet reports from stored device points.
+package reports
+
+import (
+	"math"
+	"time"
+
+	"github.com/alexbeattie/golangone/geo"
+	"github.com/alexbeattie/golangone/models"
+)
+
+// SpeedingOptions tune incident detection.
+type SpeedingOptions struct {
+	// MinMphOver is how far over the posted limit a point must be to count.
+	MinMphOver float64
+	// MaxGap ends an incident when consecutive points are further apart than this.
+	MaxGap time.Duration
+}
+
+// DefaultSpeedingOptions counts points 5 mph or more over the limit and
+// splits incidents on gaps longer than five minutes.
+var DefaultSpeedingOptions = SpeedingOptions{MinMphOver: 5, MaxGap: 5 * time.Minute}
+
+// SpeedingIncident is a run of consecutive points over the posted limit.
+// The location and limit are taken from the point with the largest overage.
+type SpeedingIncident struct {
+	DeviceID         string    `json:"device_id"`
+	StartTime        time.Time `json:"start_time"`
+	EndTime          time.Time `json:"end_time"`
+	DurationSeconds  float64   `json:"duration_seconds"`
+	MaxMphOver       float64   `json:"max_mph_over"`
+	MaxPctOver       float64   `json:"max_pct_over"`
+	MaxSpeedMph      float64   `json:"max_speed_mph"`
+	PostedSpeedLimit float64   `json:"posted_speed_limit"`
+	Lat              float64   `json:"lat"`
+	Lng              float64   `json:"lng"`
+	PointCount       int       `json:"point_count"`
+}
+
+// SpeedingIncidents groups points (one device, ordered by time) into incidents.
+func SpeedingIncidents(points []models.StoredDevicePoint, opts SpeedingOptions) []SpeedingIncident {
+	incidents := []SpeedingIncident{}
+	var cur *SpeedingIncident
+	var prev time.Time
+
+	for _, p := range points {
+		speeding := p.PostedSpeedLimit > 0 && p.MphOverPosted > 0 && p.MphOverPosted >= opts.MinMphOver
+		gap := opts.MaxGap > 0 && !prev.IsZero() && p.DtTracker.Sub(prev) > opts.MaxGap
+		prev = p.DtTracker
+
+		if cur != nil && (!speeding || gap) {
+			incidents = append(incidents, *cur)
+			cur = nil
+		}
+		if !speeding {
+			continue
+		}
+
+		if cur == nil {
+			cur = &SpeedingIncident{DeviceID: p.DeviceID, StartTime: p.DtTracker}
+		}
+		cur.EndTime = p.DtTracker
+		cur.DurationSeconds = cur.EndTime.Sub(cur.StartTime).Seconds()
+		cur.PointCount++
+		cur.MaxPctOver = math.Max(cur.MaxPctOver, p.PctOverPosted)
+		cur.MaxSpeedMph = math.Max(cur.MaxSpeedMph, p.Speed/geo.KmPerMile)
+		if p.MphOverPosted > cur.MaxMphOver {
+			cur.MaxMphOver = p.MphOverPosted
+			cur.PostedSpeedLimit = p.PostedSpeedLimit
+			cur.Lat, cur.Lng = p.Lat, p.Lng
+		}
+	}
+
+	if cur != nil {
+		incidents = append(incidents, *cur)
+	}
+	return incidents
+}
@@ -0,0 +1,99 @@
+package reports
+
+import (
+	"time"
+
+	"github.com/alexbeattie/golangone/drivestop"
+	"github.com/alexbeattie/golangone/geo"
+)
+
+// Path collects a thinned outline of a route for drawing thumbnails. Points
+// closer than MinSpacing meters to the last kept point are skipped, and when
+// more than maxPathPoints are kept every other point is dropped and the
+// spacing doubled, so memory stays bounded however long the route is.
+type Path struct {
+	MinSpacing float64
+	Points     []geo.Point
+}
+
+const maxPathPoints = 1000
+
+// Add appends a point to the path unless it is too close to the last one.
+func (p *Path) Add(lat, lng float64) {
+	if n := len(p.Points); n > 0 {
+		last := p.Points[n-1]
+		if geo.DistanceMeters(last.Lat, last.Lng, lat, lng) < p.MinSpacing {
+			return
+		}
+	}
+	p.Points = append(p.Points, geo.Point{Lat: lat, Lng: lng})
+
+	if len(p.Points) > maxPathPoints {
+		kept := p.Points[:0]
+		for i, pt := range p.Points {
+			if i%2 == 0 || i == len(p.Points)-1 {
+				kept = append(kept, pt)
+			}
+		}
+		p.Points = kept
+		p.MinSpacing *= 2
+	}
+}
+
+// DeviceSummary is one device's activity over a report window. Distance is in
+// miles or km and TopSpeed in mph or km/h, depending on the report units.
+type DeviceSummary struct {
+	DeviceID  string        `json:"device_id"`
+	Name      string        `json:"name"`
+	Distance  float64       `json:"distance"`
+	DriveTime time.Duration `json:"drive_time"`
+	IdleTime  time.Duration `json:"idle_time"`
+	StopCount int           `json:"stop_count"`
+	TopSpeed  float64       `json:"top_speed"`
+	Path      []geo.Point   `json:"path"`
+}
+
+// FleetSummary is the per-device summary of a fleet over a report window.
+type FleetSummary struct {
+	From     time.Time       `json:"from"`
+	To       time.Time       `json:"to"`
+	Imperial bool            `json:"imperial"`
+	Devices  []DeviceSummary `json:"devices"`
+}
+
+// Summarize reduces a device's drive-stop breakdown to its summary figures.
+func Summarize(t DeviceTrips, path []geo.Point) DeviceSummary {
+	s := DeviceSummary{
+		DeviceID: t.DeviceID,
+		Name:     t.Name,
+		Distance: t.Route.Distance.Value,
+		IdleTime: time.Duration(t.Route.IdleDuration.Value) * time.Second,
+		TopSpeed: t.Route.TopSpeed.Value,
+		Path:     path,
+	}
+	for _, seg := range t.Route.DriveStopList {
+		switch seg.Type {
+		case drivestop.TypeDrive:
+			s.DriveTime += time.Duration(seg.Duration.Value) * time.Second
+		case drivestop.TypeStop:
+			s.StopCount++
+		}
+	}
+	return s
+}
+
+// Totals sums the fleet's distance, drive and idle time and stops, and takes
+// the highest top speed.
+func (f *FleetSummary) Totals() DeviceSummary {
+	var total DeviceSummary
+	for _, d := range f.Devices {
+		total.Distance += d.Distance
+		total.DriveTime += d.DriveTime
+		total.IdleTime += d.IdleTime
+		total.StopCount += d.StopCount
+		if d.TopSpeed > total.TopSpeed {
+			total.TopSpeed = d.TopSpeed
+		}
+	}
+	return total
+}
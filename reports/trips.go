@@ -0,0 +1,78 @@
+package reports
+
+import (
+	"time"
+
+	"github.com/alexbeattie/golangone/geo"
+	"github.com/alexbeattie/golangone/models"
+)
+
+// DeviceTrips is one device's drive-stop breakdown over a report window.
+type DeviceTrips struct {
+	DeviceID string                    `json:"device_id"`
+	Name     string                    `json:"name"`
+	Route    *models.DriveStopResponse `json:"route"`
+}
+
+// TripRow is a drive-stop segment flattened for tabular export. Distance and
+// odometer readings are in miles when the rows were built imperial, km
+// otherwise; Distance is zero for idles and stops.
+type TripRow struct {
+	DeviceID     string
+	Name         string
+	Type         string
+	Start        time.Time
+	End          time.Time
+	Duration     time.Duration
+	StartLat     float64
+	StartLng     float64
+	EndLat       float64
+	EndLng       float64
+	Distance     float64
+	OdometerFrom float64
+	OdometerTo   float64
+}
+
+// TripRows flattens trips into one row per segment, converting odometer
+// readings to the requested unit.
+func TripRows(trips []DeviceTrips, imperial bool) []TripRow {
+	var rows []TripRow
+	for _, t := range trips {
+		for _, seg := range t.Route.DriveStopList {
+			start, _ := time.Parse(time.RFC3339, seg.TimeFrom)
+			end, _ := time.Parse(time.RFC3339, seg.TimeTo)
+
+			row := TripRow{
+				DeviceID:     t.DeviceID,
+				Name:         t.Name,
+				Type:         seg.Type,
+				Start:        start,
+				End:          end,
+				Duration:     time.Duration(seg.Duration.Value) * time.Second,
+				StartLat:     seg.FirstLatLng.Lat,
+				StartLng:     seg.FirstLatLng.Lng,
+				EndLat:       seg.LastLatLng.Lat,
+				EndLng:       seg.LastLatLng.Lng,
+				OdometerFrom: convertDistance(seg.OdometerFrom.Value, seg.OdometerFrom.Unit, imperial),
+				OdometerTo:   convertDistance(seg.OdometerTo.Value, seg.OdometerTo.Unit, imperial),
+			}
+			if seg.Distance != nil {
+				row.Distance = convertDistance(seg.Distance.Value, seg.Distance.Unit, imperial)
+			}
+			rows = append(rows, row)
+		}
+	}
+	return rows
+}
+
+// convertDistance converts a reading in unit ("mi" or "km") to miles or km.
+// Readings in any other unit are returned unchanged.
+func convertDistance(v float64, unit string, imperial bool) float64 {
+	switch {
+	case unit == "km" && imperial:
+		return v / geo.KmPerMile
+	case unit == "mi" && !imperial:
+		return v * geo.KmPerMile
+	}
+	return v
+}
@@ -0,0 +1,207 @@
+// services/alert_engine.go
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"slices"
+	"time"
+
+	"github.com/alexbeattie/golangone/geo"
+	"github.com/alexbeattie/golangone/models"
+)
+
+const (
+	defaultOfflineTimeout = 65 * time.Minute
+)
+
+// AlertEngine evaluates every enabled alert rule after each ingestion poll.
+// Point rules (speed, low voltage, after hours) are checked against each newly
+// stored point; the offline rule is checked against each device's state. An
+// alert is raised when a rule's condition becomes true for a device and
+// resolved automatically when it clears.
+type AlertEngine struct {
+	service *Service
+}
+
+func NewAlertEngine(service *Service) *AlertEngine {
+	return &AlertEngine{service: service}
+}
+
+// condition is the outcome of evaluating a rule against one observation.
+type condition struct {
+	active  bool
+	value   float64
+	message string
+	at      time.Time
+	lat     float64
+	lng     float64
+}
+
+// DevicesPolled implements PollObserver.
+func (e *AlertEngine) DevicesPolled(ctx context.Context, devices []models.Device, stored []models.StoredDevicePoint) {
+	var rules []models.AlertRule
+	if err := e.service.db.WithContext(ctx).Where("enabled = ?", true).Find(&rules).Error; err != nil {
+		log.Printf("Alert engine: failed to load rules: %v", err)
+		return
+	}
+	if len(rules) == 0 {
+		return
+	}
+
+	points := make(map[string][]models.StoredDevicePoint)
+	for _, p := range stored {
+		points[p.DeviceID] = append(points[p.DeviceID], p)
+	}
+
+	for _, rule := range rules {
+		for _, d := range devices {
+			if len(rule.DeviceIDs) > 0 && !slices.Contains(rule.DeviceIDs, d.DeviceID) {
+				continue
+			}
+
+			if rule.Type == models.RuleOffline {
+				e.apply(ctx, rule, d.DeviceID, evaluateOffline(rule, d))
+				continue
+			}
+			for _, p := range points[d.DeviceID] {
+				cond, ok := evaluatePoint(rule, p)
+				if ok {
+					e.apply(ctx, rule, d.DeviceID, cond)
+				}
+			}
+		}
+	}
+}
+
+// apply raises or resolves the rule's alert for a device as cond requires.
+func (e *AlertEngine) apply(ctx context.Context, rule models.AlertRule, deviceID string, cond condition) {
+	open, err := e.service.openAlert(ctx, rule.ID, deviceID)
+	if err != nil {
+		log.Printf("Alert engine: %v", err)
+		return
+	}
+
+	switch {
+	case cond.active && open == nil:
+		ruleID := rule.ID
+		err = e.service.raiseAlert(ctx, &models.Alert{
+			RuleID:      &ruleID,
+			UserID:      rule.UserID,
+			DeviceID:    deviceID,
+			Type:        rule.Type,
+			Severity:    rule.Severity,
+			Message:     cond.message,
+			Value:       cond.value,
+			Lat:         cond.lat,
+			Lng:         cond.lng,
+			TriggeredAt: cond.at,
+		})
+	case !cond.active && open != nil:
+		err = e.service.resolveAlert(ctx, open, cond.at)
+	}
+	if err != nil {
+		log.Printf("Alert engine: %v", err)
+	}
+}
+
+// evaluatePoint checks a point rule. It reports false when the point carries
+// no reading for the rule, which leaves any open alert untouched.
+func evaluatePoint(rule models.AlertRule, p models.StoredDevicePoint) (condition, bool) {
+	cond := condition{at: p.DtTracker, lat: p.Lat, lng: p.Lng}
+
+	switch rule.Type {
+	case models.RuleSpeed:
+		speed := p.Speed
+		if rule.Unit == "mph" {
+			speed /= geo.KmPerMile
+		}
+		cond.value = speed
+		cond.active = speed > rule.Threshold
+		cond.message = fmt.Sprintf("Speed %.0f %s exceeds %.0f %s", speed, rule.Unit, rule.Threshold, rule.Unit)
+		return cond, true
+
+	case models.RuleLowVoltage:
+		// Devices without a voltage input report 0; treat that as no reading.
+		if p.ExternalVolt <= 0 {
+			return cond, false
+		}
+		cond.value = p.ExternalVolt
+		cond.active = p.ExternalVolt < rule.Threshold
+		cond.message = fmt.Sprintf("External voltage %.2f V is below %.2f V", p.ExternalVolt, rule.Threshold)
+		return cond, true
+
+	case models.RuleAfterHours:
+		cond.active = p.Acc && !withinBusinessHours(rule, p.DtTracker)
+		cond.message = "Ignition on outside business hours"
+		return cond, true
+	}
+	return cond, false
+}
+
+// evaluateOffline checks whether a device has been offline for longer than
+// the rule's duration, or the device's own offline_timeout setting. The time
+// of the last point is taken as the moment the device went silent.
+func evaluateOffline(rule models.AlertRule, d models.Device) condition {
+	now := time.Now().UTC()
+	p := d.LatestDevicePoint
+	cond := condition{at: now, lat: p.Lat, lng: p.Lng}
+	if d.Online {
+		return cond
+	}
+
+	timeout := time.Duration(rule.DurationSeconds) * time.Second
+	if timeout == 0 {
+		timeout = offlineTimeout(d)
+	}
+
+	last, err := time.Parse(time.RFC3339, p.DtTracker)
+	if err != nil {
+		return cond
+	}
+
+	silent := now.Sub(last)
+	cond.value = silent.Seconds()
+	cond.active = silent > timeout
+	cond.message = fmt.Sprintf("Device offline for %s", silent.Round(time.Minute))
+	return cond
+}
+
+// offlineTimeout reads settings.offline_timeout from a device.
+func offlineTimeout(d models.Device) time.Duration {
+	timeout, ok := models.ParseDeviceSettings(d.Settings).OfflineTimeout.Duration()
+	if !ok || timeout <= 0 {
+		return defaultOfflineTimeout
+	}
+	return timeout
+}
+
+// withinBusinessHours reports whether t falls inside the rule's business
+// hours. BusinessDays are the days a window opens on: a window whose end is
+// before its start runs past midnight into the next day, and one whose end
+// equals its start lasts 24 hours.
+func withinBusinessHours(rule models.AlertRule, t time.Time) bool {
+	loc, err := time.LoadLocation(rule.Timezone)
+	if err != nil {
+		loc = time.UTC
+	}
+	local := t.In(loc)
+	today := slices.Contains(rule.BusinessDays, int(local.Weekday()))
+	yesterday := slices.Contains(rule.BusinessDays, int(local.AddDate(0, 0, -1).Weekday()))
+
+	start, err1 := time.Parse("15:04", rule.BusinessHoursStart)
+	end, err2 := time.Parse("15:04", rule.BusinessHoursEnd)
+	if err1 != nil || err2 != nil {
+		return today
+	}
+
+	minute := local.Hour()*60 + local.Minute()
+	from := start.Hour()*60 + start.Minute()
+	to := end.Hour()*60 + end.Minute()
+	if from < to {
+		return today && minute >= from && minute < to
+	}
+	// The window wraps: the part after midnight belongs to the previous day.
+	return (today && minute >= from) || (yesterday && minute < to)
+}
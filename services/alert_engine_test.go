@@ -0,0 +1,54 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/alexbeattie/golangone/models"
+)
+
+func TestWithinBusinessHours(t *testing.T) {
+	weekdays := []int{1, 2, 3, 4, 5}
+	rule := func(start, end string) models.AlertRule {
+		return models.AlertRule{
+			BusinessHoursStart: start,
+			BusinessHoursEnd:   end,
+			BusinessDays:       weekdays,
+			Timezone:           "UTC",
+		}
+	}
+	// March 2, 2026 is a Monday.
+	at := func(day, hour, min int) time.Time {
+		return time.Date(2026, 3, day, hour, min, 0, 0, time.UTC)
+	}
+
+	tests := []struct {
+		name string
+		rule models.AlertRule
+		t    time.Time
+		want bool
+	}{
+		{name: "day window, inside", rule: rule("08:00", "17:00"), t: at(2, 9, 0), want: true},
+		{name: "day window, at the end", rule: rule("08:00", "17:00"), t: at(2, 17, 0), want: false},
+		{name: "day window, before the start", rule: rule("08:00", "17:00"), t: at(2, 7, 59), want: false},
+		{name: "day window, on a weekend", rule: rule("08:00", "17:00"), t: at(7, 9, 0), want: false},
+		{name: "overnight, evening of a business day", rule: rule("22:00", "06:00"), t: at(6, 23, 0), want: true},
+		{name: "overnight, morning after a business day", rule: rule("22:00", "06:00"), t: at(7, 3, 0), want: true},
+		{name: "overnight, morning after a weekend day", rule: rule("22:00", "06:00"), t: at(2, 3, 0), want: false},
+		{name: "overnight, evening of a weekend day", rule: rule("22:00", "06:00"), t: at(8, 23, 0), want: false},
+		{name: "overnight, between the parts", rule: rule("22:00", "06:00"), t: at(3, 12, 0), want: false},
+		{name: "24 hours from midnight", rule: rule("00:00", "00:00"), t: at(4, 23, 59), want: true},
+		{name: "24 hours from midnight, on a weekend", rule: rule("00:00", "00:00"), t: at(7, 12, 0), want: false},
+		{name: "24 hours from 09:00 runs into the next day", rule: rule("09:00", "09:00"), t: at(7, 8, 0), want: true},
+		{name: "24 hours from 09:00, before the first window", rule: rule("09:00", "09:00"), t: at(2, 8, 0), want: false},
+		{name: "no hours covers whole business days", rule: rule("", ""), t: at(2, 3, 0), want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := withinBusinessHours(tt.rule, tt.t); got != tt.want {
+				t.Errorf("withinBusinessHours(%s) = %v, want %v", tt.t.Format("Mon 15:04"), got, tt.want)
+			}
+		})
+	}
+}
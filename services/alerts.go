@@ -0,0 +1,272 @@
+// services/alerts.go
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/alexbeattie/golangone/models"
+)
+
+var (
+	// ErrInvalidAlertRule wraps every alert rule validation failure.
+	ErrInvalidAlertRule = errors.New("invalid alert rule")
+	// ErrAlertResolved is returned when acknowledging an already resolved alert.
+	ErrAlertResolved = errors.New("alert is already resolved")
+)
+
+func invalidRule(format string, args ...interface{}) error {
+	return fmt.Errorf("%w: %s", ErrInvalidAlertRule, fmt.Sprintf(format, args...))
+}
+
+// validateAlertRule fills in defaults and checks the fields required by the rule type.
+func validateAlertRule(r *models.AlertRule) error {
+	switch r.Severity {
+	case "":
+		r.Severity = models.SeverityWarning
+	case models.SeverityInfo, models.SeverityWarning, models.SeverityCritical:
+	default:
+		return invalidRule("unknown severity %q", r.Severity)
+	}
+
+	switch r.Type {
+	case models.RuleSpeed:
+		if r.Unit == "" {
+			r.Unit = "mph"
+		}
+		if r.Unit != "mph" && r.Unit != "km/h" {
+			return invalidRule("unit must be mph or km/h")
+		}
+		if r.Threshold <= 0 {
+			return invalidRule("threshold must be positive")
+		}
+	case models.RuleLowVoltage:
+		r.Unit = "V"
+		if r.Threshold <= 0 {
+			return invalidRule("threshold must be positive")
+		}
+	case models.RuleDTC:
+	case models.RuleOffline:
+		if r.DurationSeconds < 0 {
+			return invalidRule("duration_seconds must not be negative")
+		}
+	case models.RuleAfterHours:
+		if _, err := time.Parse("15:04", r.BusinessHoursStart); err != nil {
+			return invalidRule("business_hours_start must be HH:MM")
+		}
+		if _, err := time.Parse("15:04", r.BusinessHoursEnd); err != nil {
+			return invalidRule("business_hours_end must be HH:MM")
+		}
+		if r.Timezone == "" {
+			r.Timezone = "UTC"
+		}
+		if _, err := time.LoadLocation(r.Timezone); err != nil {
+			return invalidRule("unknown timezone %q", r.Timezone)
+		}
+		if len(r.BusinessDays) == 0 {
+			r.BusinessDays = []int{1, 2, 3, 4, 5}
+		}
+		for _, d := range r.BusinessDays {
+			if d < 0 || d > 6 {
+				return invalidRule("business_days must be 0 (Sunday) to 6 (Saturday)")
+			}
+		}
+	default:
+		return invalidRule("unknown type %q", r.Type)
+	}
+	return nil
+}
+
+func (s *Service) ListAlertRules(userID string) ([]models.AlertRule, error) {
+	rules := []models.AlertRule{}
+	if err := s.db.Where("user_id = ?", userID).Order("id").Find(&rules).Error; err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+func (s *Service) GetAlertRule(userID string, id uint) (*models.AlertRule, error) {
+	var r models.AlertRule
+	if err := s.db.Where("user_id = ?", userID).First(&r, id).Error; err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
+
+func (s *Service) CreateAlertRule(r *models.AlertRule) error {
+	if err := validateAlertRule(r); err != nil {
+		return err
+	}
+	return s.db.Create(r).Error
+}
+
+func (s *Service) UpdateAlertRule(r *models.AlertRule) error {
+	existing, err := s.GetAlertRule(r.UserID, r.ID)
+	if err != nil {
+		return err
+	}
+	if err := validateAlertRule(r); err != nil {
+		return err
+	}
+	r.CreatedAt = existing.CreatedAt
+	if r.Enabled {
+		return s.db.Save(r).Error
+	}
+	// A disabled rule is not evaluated, so its open alerts are resolved as
+	// when it is deleted.
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(r).Error; err != nil {
+			return err
+		}
+		return s.resolveRuleAlertsTx(tx, r.ID)
+	})
+}
+
+func (s *Service) DeleteAlertRule(userID string, id uint) error {
+	if _, err := s.GetAlertRule(userID, id); err != nil {
+		return err
+	}
+	// Nothing will evaluate the rule again, so its open alerts are resolved
+	// with it rather than left open forever.
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := s.resolveRuleAlertsTx(tx, id); err != nil {
+			return err
+		}
+		return tx.Delete(&models.AlertRule{}, id).Error
+	})
+}
+
+// resolveRuleAlertsTx resolves every unresolved alert of a rule within tx.
+func (s *Service) resolveRuleAlertsTx(tx *gorm.DB, ruleID uint) error {
+	var open []models.Alert
+	if err := tx.Where("rule_id = ? AND status <> ?", ruleID, models.AlertResolved).Find(&open).Error; err != nil {
+		return err
+	}
+	now := time.Now().UTC()
+	for i := range open {
+		if err := s.resolveAlertTx(tx, &open[i], now); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ListAlerts returns a user's alerts, newest first, optionally filtered by
+// status and device.
+func (s *Service) ListAlerts(userID, status, deviceID string, limit int) ([]models.Alert, error) {
+	if limit <= 0 || limit > MaxEventLimit {
+		limit = DefaultEventLimit
+	}
+
+	query := s.db.Where("user_id = ?", userID)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if deviceID != "" {
+		query = query.Where("device_id = ?", deviceID)
+	}
+
+	alerts := []models.Alert{}
+	if err := query.Order("triggered_at DESC, id DESC").Limit(limit).Find(&alerts).Error; err != nil {
+		return nil, err
+	}
+	return alerts, nil
+}
+
+// AcknowledgeAlert marks an open alert as seen by a user.
+func (s *Service) AcknowledgeAlert(userID string, id uint, by string) (*models.Alert, error) {
+	var a models.Alert
+	if err := s.db.Where("user_id = ?", userID).First(&a, id).Error; err != nil {
+		return nil, err
+	}
+	if a.Status == models.AlertResolved {
+		return nil, ErrAlertResolved
+	}
+	if a.Status == models.AlertAcknowledged {
+		return &a, nil
+	}
+
+	now := time.Now().UTC()
+	a.Status, a.AcknowledgedAt, a.AcknowledgedBy = models.AlertAcknowledged, &now, by
+	if err := s.db.Save(&a).Error; err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
+
+// ResolveAlert closes an alert by hand. Resolving twice is a no-op.
+func (s *Service) ResolveAlert(userID string, id uint) (*models.Alert, error) {
+	var a models.Alert
+	if err := s.db.Where("user_id = ?", userID).First(&a, id).Error; err != nil {
+		return nil, err
+	}
+	if a.Status == models.AlertResolved {
+		return &a, nil
+	}
+	if err := s.resolveAlert(context.Background(), &a, time.Now().UTC()); err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
+
+// raiseAlert opens a, stamping TriggeredAt if unset, and queues its webhook
+// deliveries with it. The alert email is queued after commit; failing to
+// queue it does not fail the alert.
+func (s *Service) raiseAlert(ctx context.Context, a *models.Alert) error {
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return s.raiseAlertTx(tx, a)
+	})
+	if err != nil {
+		return err
+	}
+	s.queueAlertEmail(ctx, a)
+	return nil
+}
+
+// raiseAlertTx opens a within tx. The caller queues the alert email once tx
+// has committed.
+func (s *Service) raiseAlertTx(tx *gorm.DB, a *models.Alert) error {
+	a.Status = models.AlertOpen
+	if a.TriggeredAt.IsZero() {
+		a.TriggeredAt = time.Now().UTC()
+	}
+	if err := tx.Create(a).Error; err != nil {
+		return fmt.Errorf("failed to store %s alert: %w", a.Type, err)
+	}
+	return s.publish(tx, models.NotifyAlert, a)
+}
+
+func (s *Service) resolveAlert(ctx context.Context, a *models.Alert, at time.Time) error {
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return s.resolveAlertTx(tx, a, at)
+	})
+}
+
+// resolveAlertTx resolves a and queues its webhook deliveries within tx.
+func (s *Service) resolveAlertTx(tx *gorm.DB, a *models.Alert, at time.Time) error {
+	a.Status, a.ResolvedAt = models.AlertResolved, &at
+	if err := tx.Save(a).Error; err != nil {
+		return fmt.Errorf("failed to resolve alert %d: %w", a.ID, err)
+	}
+	return s.publish(tx, models.NotifyAlertResolved, a)
+}
+
+// openAlert returns the unresolved alert for a rule and device, if any.
+func (s *Service) openAlert(ctx context.Context, ruleID uint, deviceID string) (*models.Alert, error) {
+	var a models.Alert
+	err := s.db.WithContext(ctx).
+		Where("rule_id = ? AND device_id = ? AND status <> ?", ruleID, deviceID, models.AlertResolved).
+		Order("id DESC").
+		First(&a).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
@@ -0,0 +1,121 @@
+// services/breaker.go
+package services
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is returned while the breaker is refusing upstream calls.
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+// Circuit breaker states.
+const (
+	BreakerClosed   = "closed"
+	BreakerOpen     = "open"
+	BreakerHalfOpen = "half_open"
+)
+
+// BreakerSnapshot is a point-in-time view of a CircuitBreaker for health reporting.
+type BreakerSnapshot struct {
+	State               string     `json:"state"`
+	ConsecutiveFailures int        `json:"consecutive_failures"`
+	OpenedAt            *time.Time `json:"opened_at,omitempty"`
+	RetryAt             *time.Time `json:"retry_at,omitempty"`
+}
+
+// CircuitBreaker opens after threshold consecutive failures and rejects calls
+// for cooldown. After the cooldown a single trial call is let through
+// (half-open); its outcome closes or re-opens the breaker.
+type CircuitBreaker struct {
+	mu        sync.Mutex
+	threshold int
+	cooldown  time.Duration
+	state     string
+	failures  int
+	openedAt  time.Time
+	trialBusy bool
+}
+
+func NewCircuitBreaker(threshold int, cooldown time.Duration) *CircuitBreaker {
+	if threshold < 1 {
+		threshold = 1
+	}
+	return &CircuitBreaker{
+		threshold: threshold,
+		cooldown:  cooldown,
+		state:     BreakerClosed,
+	}
+}
+
+// Allow reports whether a call may proceed, returning ErrCircuitOpen if not.
+func (b *CircuitBreaker) Allow() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case BreakerOpen:
+		if time.Since(b.openedAt) < b.cooldown {
+			return ErrCircuitOpen
+		}
+		b.state = BreakerHalfOpen
+		b.trialBusy = true
+		return nil
+	case BreakerHalfOpen:
+		if b.trialBusy {
+			return ErrCircuitOpen
+		}
+		b.trialBusy = true
+		return nil
+	default:
+		return nil
+	}
+}
+
+// Success records a successful call and closes the breaker.
+func (b *CircuitBreaker) Success() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.state = BreakerClosed
+	b.failures = 0
+	b.trialBusy = false
+}
+
+// Failure records a failed call, opening the breaker once the threshold is
+// reached or immediately if the failed call was the half-open trial.
+func (b *CircuitBreaker) Failure() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures++
+	b.trialBusy = false
+	if b.state == BreakerHalfOpen || b.failures >= b.threshold {
+		b.state = BreakerOpen
+		b.openedAt = time.Now()
+	}
+}
+
+// Release ends a call without recording an outcome, such as one its caller
+// cancelled, freeing the half-open trial for the next call.
+func (b *CircuitBreaker) Release() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.trialBusy = false
+}
+
+func (b *CircuitBreaker) Snapshot() BreakerSnapshot {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	snap := BreakerSnapshot{State: b.state, ConsecutiveFailures: b.failures}
+	if b.state != BreakerClosed {
+		openedAt := b.openedAt
+		retryAt := openedAt.Add(b.cooldown)
+		snap.OpenedAt = &openedAt
+		snap.RetryAt = &retryAt
+	}
+	return snap
+}
@@ -0,0 +1,81 @@
+// services/cache.go
+package services
+
+import (
+	"sync"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+
+	"github.com/alexbeattie/golangone/models"
+)
+
+// DeviceSnapshot is a device list as returned by the upstream at FetchedAt.
+type DeviceSnapshot struct {
+	Devices   []models.Device
+	FetchedAt time.Time
+	// Stale is set when the snapshot is older than the TTL and a refresh is in flight.
+	Stale bool
+}
+
+// deviceCache holds the last good device list. Reads within ttl are served
+// from memory; reads within ttl+maxStale get the old snapshot immediately
+// while a background refresh runs; anything older blocks on a refresh.
+// Concurrent refreshes are coalesced into a single upstream call.
+type deviceCache struct {
+	fetch    func() ([]models.Device, error)
+	ttl      time.Duration
+	maxStale time.Duration
+
+	group singleflight.Group
+
+	mu       sync.RWMutex
+	snapshot *DeviceSnapshot
+}
+
+func newDeviceCache(fetch func() ([]models.Device, error), ttl, maxStale time.Duration) *deviceCache {
+	return &deviceCache{fetch: fetch, ttl: ttl, maxStale: maxStale}
+}
+
+func (c *deviceCache) get() (DeviceSnapshot, error) {
+	c.mu.RLock()
+	snap := c.snapshot
+	c.mu.RUnlock()
+
+	if snap != nil && c.ttl > 0 {
+		age := time.Since(snap.FetchedAt)
+		if age < c.ttl {
+			return *snap, nil
+		}
+		if age < c.ttl+c.maxStale {
+			c.group.DoChan("devices", c.load)
+			stale := *snap
+			stale.Stale = true
+			return stale, nil
+		}
+	}
+
+	return c.refresh()
+}
+
+// refresh fetches a new snapshot, joining any refresh already in flight.
+func (c *deviceCache) refresh() (DeviceSnapshot, error) {
+	v, err, _ := c.group.Do("devices", c.load)
+	if err != nil {
+		return DeviceSnapshot{}, err
+	}
+	return *v.(*DeviceSnapshot), nil
+}
+
+func (c *deviceCache) load() (interface{}, error) {
+	devices, err := c.fetch()
+	if err != nil {
+		return nil, err
+	}
+
+	snap := &DeviceSnapshot{Devices: devices, FetchedAt: time.Now()}
+	c.mu.Lock()
+	c.snapshot = snap
+	c.mu.Unlock()
+	return snap, nil
+}
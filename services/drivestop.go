@@ -0,0 +1,107 @@
+// services/drivestop.go
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/alexbeattie/golangone/drivestop"
+	"github.com/alexbeattie/golangone/models"
+)
+
+// DeviceSettings returns the segmentation settings stored for a device, or the
+// defaults if the device has not been ingested yet.
+func (s *Service) DeviceSettings(deviceID string) (drivestop.Settings, error) {
+	var record models.DeviceRecord
+	err := s.db.First(&record, "device_id = ?", deviceID).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return drivestop.DefaultSettings, nil
+	}
+	if err != nil {
+		return drivestop.Settings{}, fmt.Errorf("failed to load device settings: %w", err)
+	}
+	return drivestop.SettingsFromDevice(models.ParseDeviceSettings(record.Settings)), nil
+}
+
+// StoredPoints returns all stored points of a device in [from, to], oldest first.
+func (s *Service) StoredPoints(deviceID string, from, to time.Time) ([]models.StoredDevicePoint, error) {
+	var points []models.StoredDevicePoint
+	err := s.db.Where("device_id = ? AND dt_tracker BETWEEN ? AND ?", deviceID, from, to).
+		Order("dt_tracker, id").
+		Find(&points).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to load points: %w", err)
+	}
+	return points, nil
+}
+
+// EachStoredPoint calls fn with each stored point of a device in [from, to],
+// oldest first, reading rows one at a time so long windows are never held in
+// memory. It stops at the first error from fn.
+func (s *Service) EachStoredPoint(ctx context.Context, deviceID string, from, to time.Time, fn func(models.StoredDevicePoint) error) error {
+	rows, err := s.db.WithContext(ctx).Model(&models.StoredDevicePoint{}).
+		Where("device_id = ? AND dt_tracker BETWEEN ? AND ?", deviceID, from, to).
+		Order("dt_tracker, id").
+		Rows()
+	if err != nil {
+		return fmt.Errorf("failed to load points: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var p models.StoredDevicePoint
+		if err := s.db.ScanRows(rows, &p); err != nil {
+			return fmt.Errorf("failed to read point: %w", err)
+		}
+		if err := fn(p); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// LatestPointTime returns the time of a device's newest stored point in
+// [from, to], or false if there is none.
+func (s *Service) LatestPointTime(ctx context.Context, deviceID string, from, to time.Time) (time.Time, bool, error) {
+	var latest sql.NullTime
+	err := s.db.WithContext(ctx).Model(&models.StoredDevicePoint{}).
+		Where("device_id = ? AND dt_tracker BETWEEN ? AND ?", deviceID, from, to).
+		Select("MAX(dt_tracker)").
+		Scan(&latest).Error
+	if err != nil {
+		return time.Time{}, false, fmt.Errorf("failed to load latest point: %w", err)
+	}
+	return latest.Time, latest.Valid, nil
+}
+
+// ComputeDriveStops segments a device's stored history locally using its own settings.
+func (s *Service) ComputeDriveStops(ctx context.Context, deviceID string, from, to time.Time, opts drivestop.Options) (*models.DriveStopResponse, error) {
+	settings, err := s.DeviceSettings(deviceID)
+	if err != nil {
+		return nil, err
+	}
+
+	seg := drivestop.NewSegmenter(settings)
+	err = s.EachStoredPoint(ctx, deviceID, from, to, func(p models.StoredDevicePoint) error {
+		seg.Add(p)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return seg.Result(from, to, opts), nil
+}
+
+// DeviceName returns a device's display name, falling back to its ID.
+func (s *Service) DeviceName(deviceID string) string {
+	var record models.DeviceRecord
+	if err := s.db.Select("display_name").First(&record, "device_id = ?", deviceID).Error; err != nil || record.DisplayName == "" {
+		return deviceID
+	}
+	return record.DisplayName
+}
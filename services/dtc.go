@@ -0,0 +1,216 @@
+// services/dtc.go
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"slices"
+
+	"gorm.io/gorm"
+
+	"github.com/alexbeattie/golangone/dtc"
+	"github.com/alexbeattie/golangone/models"
+)
+
+// DTCTracker keeps DeviceDTC records in step with the trouble codes reported
+// on newly ingested points. A code not seen before opens a record, raises a
+// "new" event and alerts every user with a matching dtc rule; a code that is
+// no longer reported is marked cleared and its alerts resolved.
+type DTCTracker struct {
+	service *Service
+}
+
+func NewDTCTracker(service *Service) *DTCTracker {
+	return &DTCTracker{service: service}
+}
+
+// DevicesPolled implements PollObserver.
+func (t *DTCTracker) DevicesPolled(ctx context.Context, devices []models.Device, stored []models.StoredDevicePoint) {
+	fresh := make(map[string]models.StoredDevicePoint, len(stored))
+	for _, p := range stored {
+		fresh[p.DevicePointID] = p
+	}
+
+	for _, device := range devices {
+		point := device.LatestDevicePoint
+		sp, ok := fresh[point.DevicePointID]
+		// A nil list means the point carried no DTC report at all.
+		if !ok || point.DevicePointDetail.DtcList.Codes == nil {
+			continue
+		}
+		if err := t.sync(ctx, sp, point.DevicePointDetail.DtcList.Codes); err != nil {
+			log.Printf("DTC tracker: %v", err)
+		}
+	}
+}
+
+func (t *DTCTracker) sync(ctx context.Context, p models.StoredDevicePoint, codes []models.DTC) error {
+	db := t.service.db.WithContext(ctx)
+
+	var active []models.DeviceDTC
+	if err := db.Where("device_id = ? AND cleared_at IS NULL", p.DeviceID).Find(&active).Error; err != nil {
+		return fmt.Errorf("failed to load active codes: %w", err)
+	}
+
+	reported := make(map[string]bool)
+	for _, d := range codes {
+		if code := dtc.Normalize(d.Code); code != "" {
+			reported[code] = true
+		}
+	}
+
+	for i := range active {
+		rec := &active[i]
+		if !reported[rec.Code] {
+			if err := t.clear(ctx, p, rec); err != nil {
+				return err
+			}
+			continue
+		}
+		delete(reported, rec.Code)
+		rec.LastSeen = p.DtTracker
+		if err := db.Save(rec).Error; err != nil {
+			return fmt.Errorf("failed to update code %s: %w", rec.Code, err)
+		}
+	}
+
+	for code := range reported {
+		if err := t.open(ctx, p, code); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// open stores a newly reported code with its "new" event and alerts in one
+// transaction, then queues the alert emails.
+func (t *DTCTracker) open(ctx context.Context, p models.StoredDevicePoint, code string) error {
+	rules, err := t.rules(ctx, p.DeviceID)
+	if err != nil {
+		return err
+	}
+
+	info := dtc.Decode(code)
+	rec := models.DeviceDTC{
+		DeviceID:    p.DeviceID,
+		Code:        info.Code,
+		System:      info.System,
+		Description: info.Description,
+		FirstSeen:   p.DtTracker,
+		LastSeen:    p.DtTracker,
+	}
+	var alerts []*models.Alert
+	err = t.service.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		alerts = nil
+		if err := tx.Create(&rec).Error; err != nil {
+			return fmt.Errorf("failed to store code %s: %w", code, err)
+		}
+		if err := t.service.recordEventTx(tx, t.event(p, models.EventDTCNew, rec)); err != nil {
+			return err
+		}
+		for _, rule := range rules {
+			ruleID, recID := rule.ID, rec.ID
+			a := &models.Alert{
+				RuleID:      &ruleID,
+				UserID:      rule.UserID,
+				DeviceID:    p.DeviceID,
+				Type:        models.RuleDTC,
+				Severity:    rule.Severity,
+				DeviceDTCID: &recID,
+				Message:     fmt.Sprintf("New trouble code %s: %s", rec.Code, rec.Description),
+				Lat:         p.Lat,
+				Lng:         p.Lng,
+				TriggeredAt: p.DtTracker,
+			}
+			if err := t.service.raiseAlertTx(tx, a); err != nil {
+				return err
+			}
+			alerts = append(alerts, a)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	for _, a := range alerts {
+		t.service.queueAlertEmail(ctx, a)
+	}
+	return nil
+}
+
+// clear marks a code no longer reported as cleared, records the "cleared"
+// event and resolves the code's open alerts in one transaction.
+func (t *DTCTracker) clear(ctx context.Context, p models.StoredDevicePoint, rec *models.DeviceDTC) error {
+	clearedAt := p.DtTracker
+	rec.ClearedAt = &clearedAt
+	return t.service.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(rec).Error; err != nil {
+			return fmt.Errorf("failed to update code %s: %w", rec.Code, err)
+		}
+		if err := t.service.recordEventTx(tx, t.event(p, models.EventDTCCleared, *rec)); err != nil {
+			return err
+		}
+
+		var open []models.Alert
+		err := tx.Where("device_dtc_id = ? AND status <> ?", rec.ID, models.AlertResolved).Find(&open).Error
+		if err != nil {
+			return fmt.Errorf("failed to load alerts for code %s: %w", rec.Code, err)
+		}
+		for i := range open {
+			if err := t.service.resolveAlertTx(tx, &open[i], clearedAt); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (t *DTCTracker) event(p models.StoredDevicePoint, subtype string, rec models.DeviceDTC) *models.Event {
+	return &models.Event{
+		Type:       models.EventTypeDTC,
+		Subtype:    subtype,
+		DeviceID:   p.DeviceID,
+		OccurredAt: p.DtTracker,
+		Lat:        p.Lat,
+		Lng:        p.Lng,
+		Data: map[string]interface{}{
+			"code":        rec.Code,
+			"system":      rec.System,
+			"description": rec.Description,
+		},
+	}
+}
+
+// rules returns the enabled dtc rules covering the device.
+func (t *DTCTracker) rules(ctx context.Context, deviceID string) ([]models.AlertRule, error) {
+	var all []models.AlertRule
+	err := t.service.db.WithContext(ctx).
+		Where("type = ? AND enabled = ?", models.RuleDTC, true).
+		Find(&all).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to load dtc rules: %w", err)
+	}
+
+	var rules []models.AlertRule
+	for _, rule := range all {
+		if len(rule.DeviceIDs) == 0 || slices.Contains(rule.DeviceIDs, deviceID) {
+			rules = append(rules, rule)
+		}
+	}
+	return rules, nil
+}
+
+// ListDeviceDTCs returns a device's trouble codes, most recently seen first.
+func (s *Service) ListDeviceDTCs(deviceID string, activeOnly bool) ([]models.DeviceDTC, error) {
+	query := s.db.Where("device_id = ?", deviceID)
+	if activeOnly {
+		query = query.Where("cleared_at IS NULL")
+	}
+
+	codes := []models.DeviceDTC{}
+	if err := query.Order("last_seen DESC, id DESC").Find(&codes).Error; err != nil {
+		return nil, fmt.Errorf("failed to load trouble codes: %w", err)
+	}
+	return codes, nil
+}
@@ -0,0 +1,260 @@
+// services/email.go
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/alexbeattie/golangone/email"
+	"github.com/alexbeattie/golangone/models"
+)
+
+const (
+	emailFlushInterval = 30 * time.Second
+	// emailClaimLease is how long claimed alerts are hidden from other
+	// notifiers; it must outlast sending them.
+	emailClaimLease = 5 * time.Minute
+)
+
+// ErrEmailNotConfigured is returned for anything that needs email when no SMTP
+// relay is configured.
+var ErrEmailNotConfigured = errors.New("email is not configured")
+
+var severityRank = map[string]int{
+	models.SeverityInfo:     0,
+	models.SeverityWarning:  1,
+	models.SeverityCritical: 2,
+}
+
+// userPreferences returns the preferences stored for userID, or nil if the
+// user has none.
+func (s *Service) userPreferences(ctx context.Context, userID string) (*models.UserPreferences, error) {
+	var prefs models.UserPreferences
+	err := s.db.WithContext(ctx).Where("user_id = ?", userID).First(&prefs).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &prefs, nil
+}
+
+// wantsAlertEmail reports whether prefs opt in to email for an alert of the
+// given severity.
+func wantsAlertEmail(prefs *models.UserPreferences, severity string) bool {
+	if prefs == nil || !prefs.EmailAlerts || prefs.Email == "" {
+		return false
+	}
+	return severityRank[severity] >= severityRank[prefs.EmailMinSeverity]
+}
+
+// queueAlertEmail queues a raised alert for the email notifier if its owner
+// has opted in. Failures are logged; email never blocks alerting.
+func (s *Service) queueAlertEmail(ctx context.Context, a *models.Alert) {
+	if s.mailer == nil {
+		return
+	}
+	prefs, err := s.userPreferences(ctx, a.UserID)
+	if err != nil {
+		log.Printf("Failed to load preferences for user %s: %v", a.UserID, err)
+		return
+	}
+	if !wantsAlertEmail(prefs, a.Severity) {
+		return
+	}
+
+	pending := models.PendingAlertEmail{UserID: a.UserID, AlertID: a.ID}
+	if err := s.db.WithContext(ctx).Create(&pending).Error; err != nil {
+		log.Printf("Failed to queue email for alert %d: %v", a.ID, err)
+	}
+}
+
+// RunEmailNotifier sends queued alert emails until ctx is cancelled. Users in
+// immediate mode get one email per alert unless more than EmailBurstLimit are
+// queued, in which case they are batched into a digest; digest users get one
+// email once their oldest queued alert is EmailDigestInterval old.
+func (s *Service) RunEmailNotifier(ctx context.Context) {
+	if s.mailer == nil {
+		return
+	}
+
+	ticker := time.NewTicker(emailFlushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		var users []string
+		if err := s.db.WithContext(ctx).Model(&models.PendingAlertEmail{}).
+			Distinct("user_id").Pluck("user_id", &users).Error; err != nil {
+			log.Printf("Email notifier: %v", err)
+			continue
+		}
+		for _, userID := range users {
+			if err := s.flushAlertEmails(ctx, userID); err != nil {
+				log.Printf("Email notifier: user %s: %v", userID, err)
+			}
+		}
+	}
+}
+
+// flushAlertEmails sends a user's queued alerts if they are due. Rows are
+// claimed by setting claimed_until and committing, so the relay is never
+// contacted inside a transaction; rows are removed once their email has been
+// accepted, and anything left unsent is retried when the claim expires.
+func (s *Service) flushAlertEmails(ctx context.Context, userID string) error {
+	prefs, pending, err := s.claimAlertEmails(ctx, userID)
+	if err != nil || len(pending) == 0 {
+		return err
+	}
+
+	db := s.db.WithContext(ctx)
+	views, err := s.alertViews(db, pending)
+	if err != nil {
+		return err
+	}
+	to := []string{prefs.Email}
+
+	if prefs.EmailDigest || len(views) > s.config.EmailBurstLimit {
+		if len(views) > 0 {
+			subject, body, err := email.RenderDigest(views)
+			if err != nil {
+				return err
+			}
+			if err := s.mailer.Send(ctx, email.Message{To: to, Subject: subject, HTML: body}); err != nil {
+				return err
+			}
+		}
+		return db.Delete(&pending).Error
+	}
+
+	for i, view := range views {
+		subject, body, err := email.RenderAlert(view)
+		if err == nil {
+			err = s.mailer.Send(ctx, email.Message{To: to, Subject: subject, HTML: body})
+		}
+		if err != nil {
+			// Keep what was sent deleted so it isn't repeated next flush.
+			if i > 0 {
+				if delErr := db.Delete(pending[:i]).Error; delErr != nil {
+					return delErr
+				}
+			}
+			return err
+		}
+	}
+	return db.Delete(&pending).Error
+}
+
+// claimAlertEmails locks a user's unclaimed queued alerts with SKIP LOCKED,
+// so concurrent replicas don't send them twice, and claims them for
+// emailClaimLease if they are due. It returns nothing when there is nothing
+// to send, dropping the queue if the user has opted out since it was filled.
+func (s *Service) claimAlertEmails(ctx context.Context, userID string) (*models.UserPreferences, []models.PendingAlertEmail, error) {
+	var prefs *models.UserPreferences
+	var pending []models.PendingAlertEmail
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		now := time.Now().UTC()
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("user_id = ? AND (claimed_until IS NULL OR claimed_until <= ?)", userID, now).
+			Order("created_at").
+			Find(&pending).Error; err != nil {
+			return err
+		}
+		if len(pending) == 0 {
+			return nil
+		}
+
+		var err error
+		prefs, err = s.userPreferences(ctx, userID)
+		if err != nil {
+			return err
+		}
+		if prefs == nil || !prefs.EmailAlerts || prefs.Email == "" {
+			// Opted out since the alerts were queued.
+			err := tx.Delete(&pending).Error
+			pending = nil
+			return err
+		}
+		if prefs.EmailDigest && time.Since(pending[0].CreatedAt) < s.config.EmailDigestInterval {
+			pending = nil
+			return nil
+		}
+		return tx.Model(&pending).Update("claimed_until", now.Add(emailClaimLease)).Error
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return prefs, pending, nil
+}
+
+// alertViews loads the alerts behind queued emails, in queue order, skipping
+// alerts that have since been deleted.
+func (s *Service) alertViews(db *gorm.DB, pending []models.PendingAlertEmail) ([]email.AlertView, error) {
+	ids := make([]uint, len(pending))
+	for i, p := range pending {
+		ids[i] = p.AlertID
+	}
+
+	var alerts []models.Alert
+	if err := db.Where("id IN ?", ids).Find(&alerts).Error; err != nil {
+		return nil, fmt.Errorf("failed to load queued alerts: %w", err)
+	}
+	byID := make(map[uint]models.Alert, len(alerts))
+	deviceIDs := make([]string, 0, len(alerts))
+	for _, a := range alerts {
+		byID[a.ID] = a
+		deviceIDs = append(deviceIDs, a.DeviceID)
+	}
+
+	var records []models.DeviceRecord
+	if err := db.Where("device_id IN ?", deviceIDs).Find(&records).Error; err != nil {
+		return nil, fmt.Errorf("failed to load device names: %w", err)
+	}
+	names := make(map[string]string, len(records))
+	for _, r := range records {
+		names[r.DeviceID] = r.DisplayName
+	}
+
+	views := make([]email.AlertView, 0, len(pending))
+	for _, p := range pending {
+		a, ok := byID[p.AlertID]
+		if !ok {
+			continue
+		}
+		name := names[a.DeviceID]
+		if name == "" {
+			name = a.DeviceID
+		}
+		views = append(views, email.AlertView{
+			Type:        a.Type,
+			Severity:    a.Severity,
+			Message:     a.Message,
+			DeviceID:    a.DeviceID,
+			DeviceName:  name,
+			TriggeredAt: a.TriggeredAt,
+			Lat:         a.Lat,
+			Lng:         a.Lng,
+		})
+	}
+	return views, nil
+}
+
+// SendEmail sends msg through the configured relay.
+func (s *Service) SendEmail(ctx context.Context, msg email.Message) error {
+	if s.mailer == nil {
+		return ErrEmailNotConfigured
+	}
+	return s.mailer.Send(ctx, msg)
+}
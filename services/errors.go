@@ -0,0 +1,82 @@
+// services/errors.go
+package services
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+)
+
+// Upstream error kinds. Every error returned by OneStepGPSClient wraps exactly
+// one of these, so callers can branch with errors.Is.
+var (
+	ErrUpstreamAuth        = errors.New("upstream rejected the API key")
+	ErrUpstreamRateLimited = errors.New("upstream rate limit exceeded")
+	ErrUpstreamUnavailable = errors.New("upstream unavailable")
+	ErrUpstreamRejected    = errors.New("upstream rejected the request")
+	ErrUpstreamMalformed   = errors.New("upstream returned a malformed payload")
+)
+
+// UpstreamError describes a failed call to the tracking provider.
+type UpstreamError struct {
+	Kind       error
+	Op         string
+	StatusCode int
+	Body       string
+	// RetryAfter is the delay requested by the upstream via Retry-After, if any.
+	RetryAfter time.Duration
+	Err        error
+}
+
+func (e *UpstreamError) Error() string {
+	msg := fmt.Sprintf("%s: %v", e.Op, e.Kind)
+	if e.StatusCode != 0 {
+		msg = fmt.Sprintf("%s (status %d)", msg, e.StatusCode)
+	}
+	if e.Body != "" {
+		msg = fmt.Sprintf("%s: %s", msg, e.Body)
+	}
+	if e.Err != nil {
+		msg = fmt.Sprintf("%s: %v", msg, e.Err)
+	}
+	return msg
+}
+
+func (e *UpstreamError) Unwrap() []error {
+	if e.Err == nil {
+		return []error{e.Kind}
+	}
+	return []error{e.Kind, e.Err}
+}
+
+// redactURL strips the query, which carries the API key, from the URL in a
+// transport error so the error can be logged.
+func redactURL(err error) error {
+	var urlErr *url.Error
+	if !errors.As(err, &urlErr) {
+		return err
+	}
+	redacted := *urlErr
+	if u, parseErr := url.Parse(urlErr.URL); parseErr == nil {
+		u.RawQuery = ""
+		redacted.URL = u.String()
+	} else {
+		redacted.URL = "<redacted>"
+	}
+	return &redacted
+}
+
+// kindForStatus classifies a non-2xx upstream status code.
+func kindForStatus(status int) error {
+	switch {
+	case status == 401 || status == 403:
+		return ErrUpstreamAuth
+	case status == 429:
+		return ErrUpstreamRateLimited
+	case status >= 500:
+		return ErrUpstreamUnavailable
+	default:
+		return ErrUpstreamRejected
+	}
+}
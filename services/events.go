@@ -0,0 +1,80 @@
+// services/events.go
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/alexbeattie/golangone/models"
+)
+
+const (
+	DefaultEventLimit = 100
+	MaxEventLimit     = 1000
+)
+
+// EventQuery filters stored events. Zero values match everything.
+type EventQuery struct {
+	Type       string
+	Subtype    string
+	DeviceID   string
+	GeofenceID uint
+	From       time.Time
+	To         time.Time
+	Limit      int
+}
+
+// recordEvent stores ev and queues its webhook deliveries in the same
+// transaction, so a stored event is never missing from the outbox.
+func (s *Service) recordEvent(ctx context.Context, ev *models.Event) error {
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return s.recordEventTx(tx, ev)
+	})
+}
+
+// recordEventTx is recordEvent within a caller's transaction.
+func (s *Service) recordEventTx(tx *gorm.DB, ev *models.Event) error {
+	if err := tx.Create(ev).Error; err != nil {
+		return fmt.Errorf("failed to store %s event: %w", ev.Type, err)
+	}
+	return s.publish(tx, ev.Type, ev)
+}
+
+// ListEvents returns matching events, newest first.
+func (s *Service) ListEvents(q EventQuery) ([]models.Event, error) {
+	if q.Limit <= 0 {
+		q.Limit = DefaultEventLimit
+	}
+	if q.Limit > MaxEventLimit {
+		q.Limit = MaxEventLimit
+	}
+
+	query := s.db.Model(&models.Event{})
+	if q.Type != "" {
+		query = query.Where("type = ?", q.Type)
+	}
+	if q.Subtype != "" {
+		query = query.Where("subtype = ?", q.Subtype)
+	}
+	if q.DeviceID != "" {
+		query = query.Where("device_id = ?", q.DeviceID)
+	}
+	if q.GeofenceID != 0 {
+		query = query.Where("geofence_id = ?", q.GeofenceID)
+	}
+	if !q.From.IsZero() {
+		query = query.Where("occurred_at >= ?", q.From)
+	}
+	if !q.To.IsZero() {
+		query = query.Where("occurred_at <= ?", q.To)
+	}
+
+	events := []models.Event{}
+	if err := query.Order("occurred_at DESC, id DESC").Limit(q.Limit).Find(&events).Error; err != nil {
+		return nil, fmt.Errorf("failed to query events: %w", err)
+	}
+	return events, nil
+}
@@ -0,0 +1,122 @@
+// services/fuel.go
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/alexbeattie/golangone/drivestop"
+	"github.com/alexbeattie/golangone/models"
+	"github.com/alexbeattie/golangone/reports"
+)
+
+// ErrInvalidFuelOverride wraps every fuel override validation failure.
+var ErrInvalidFuelOverride = errors.New("invalid fuel override")
+
+// FuelSettings is a device's fuel consumption as reported upstream, its
+// local override, and the profile fuel reports use.
+type FuelSettings struct {
+	DeviceID  string                  `json:"device_id"`
+	Device    *models.FuelConsumption `json:"device"`
+	Override  *models.FuelOverride    `json:"override"`
+	Effective reports.FuelProfile     `json:"effective"`
+}
+
+func validateFuelOverride(o *models.FuelOverride) error {
+	switch o.Measurement {
+	case "", models.FuelMPG, models.FuelKmPerLitre, models.FuelLitresPer100:
+	default:
+		return fmt.Errorf("%w: measurement must be mpg, km/l or l/100km", ErrInvalidFuelOverride)
+	}
+	// Economy and cost are in measurement units, so changing the measurement
+	// without them would reinterpret the device's own values.
+	if o.Measurement != "" && (o.FuelEconomy == nil || o.FuelCost == nil) {
+		return fmt.Errorf("%w: measurement requires fuel_economy and fuel_cost", ErrInvalidFuelOverride)
+	}
+	if o.FuelEconomy != nil && *o.FuelEconomy <= 0 {
+		return fmt.Errorf("%w: fuel_economy must be positive", ErrInvalidFuelOverride)
+	}
+	if o.FuelCost != nil && *o.FuelCost < 0 {
+		return fmt.Errorf("%w: fuel_cost must not be negative", ErrInvalidFuelOverride)
+	}
+	if o.IdleRate != nil && *o.IdleRate < 0 {
+		return fmt.Errorf("%w: idle_rate must not be negative", ErrInvalidFuelOverride)
+	}
+	return nil
+}
+
+// FuelSettings returns a device's fuel settings. A device that has not been
+// ingested yet has no upstream settings; its override still applies.
+func (s *Service) FuelSettings(deviceID string) (*FuelSettings, error) {
+	var record models.DeviceRecord
+	err := s.db.First(&record, "device_id = ?", deviceID).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("failed to load device settings: %w", err)
+	}
+
+	var override *models.FuelOverride
+	var o models.FuelOverride
+	err = s.db.First(&o, "device_id = ?", deviceID).Error
+	switch {
+	case err == nil:
+		override = &o
+	case !errors.Is(err, gorm.ErrRecordNotFound):
+		return nil, fmt.Errorf("failed to load fuel override: %w", err)
+	}
+
+	fc := models.ParseDeviceSettings(record.Settings).FuelConsumption
+	return &FuelSettings{
+		DeviceID:  deviceID,
+		Device:    fc,
+		Override:  override,
+		Effective: reports.NewFuelProfile(fc, override),
+	}, nil
+}
+
+// SetFuelOverride stores a device's fuel override, replacing any existing one.
+// It returns gorm.ErrRecordNotFound for a device that has never been ingested.
+func (s *Service) SetFuelOverride(o *models.FuelOverride) error {
+	if err := validateFuelOverride(o); err != nil {
+		return err
+	}
+	var record models.DeviceRecord
+	if err := s.db.Select("device_id").First(&record, "device_id = ?", o.DeviceID).Error; err != nil {
+		return err
+	}
+	return s.db.Save(o).Error
+}
+
+// DeleteFuelOverride removes a device's fuel override.
+func (s *Service) DeleteFuelOverride(deviceID string) error {
+	result := s.db.Delete(&models.FuelOverride{}, "device_id = ?", deviceID)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// FuelReport estimates fuel use and cost for one device, or every device with
+// stored points in the window when deviceID is empty, per period in loc.
+func (s *Service) FuelReport(ctx context.Context, deviceID string, from, to time.Time, opts drivestop.Options, period string, loc *time.Location) ([]reports.FuelRow, error) {
+	trips, err := s.TripReport(ctx, deviceID, from, to, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	rows := []reports.FuelRow{}
+	for _, t := range trips {
+		settings, err := s.FuelSettings(t.DeviceID)
+		if err != nil {
+			return nil, err
+		}
+		rows = append(rows, reports.FuelRows(t, settings.Effective, from, to, period, loc, opts.Imperial)...)
+	}
+	return rows, nil
+}
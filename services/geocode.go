@@ -0,0 +1,185 @@
+// services/geocode.go
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"strconv"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/alexbeattie/golangone/config"
+	"github.com/alexbeattie/golangone/geocode"
+	"github.com/alexbeattie/golangone/models"
+)
+
+// ErrGeocodingDisabled is returned when no geocoding provider is configured.
+var ErrGeocodingDisabled = errors.New("geocoding is not configured")
+
+const (
+	// maxConcurrentGeocodes bounds the lookups in flight while enriching a device list.
+	maxConcurrentGeocodes = 8
+	// geocodeTimeout bounds a shared provider lookup, including its wait on
+	// the rate limiter.
+	geocodeTimeout = 30 * time.Second
+)
+
+// newGeocoder returns the provider selected in config, or nil if geocoding is off.
+func newGeocoder(cfg *config.Config) geocode.Provider {
+	switch cfg.Geocoder {
+	case "stub":
+		return geocode.StubProvider{}
+	case "google":
+		return geocode.NewGoogleProvider(cfg.GoogleMapsAPIKey)
+	case "offline":
+		p, err := geocode.NewOfflineProvider(cfg.GeocoderDataset, cfg.GeocodeMaxDistance)
+		if err != nil {
+			log.Printf("Failed to load offline geocoder, reverse geocoding disabled: %v", err)
+			return nil
+		}
+		log.Printf("Offline geocoder loaded %d places", p.Len())
+		return p
+	case "":
+		if cfg.GoogleMapsAPIKey != "" {
+			return geocode.NewGoogleProvider(cfg.GoogleMapsAPIKey)
+		}
+		return nil
+	case "none":
+		return nil
+	default:
+		log.Printf("Unknown geocoder %q, reverse geocoding disabled", cfg.Geocoder)
+		return nil
+	}
+}
+
+func newGeocodeLimiter(perSecond float64) *rate.Limiter {
+	if perSecond <= 0 {
+		return rate.NewLimiter(rate.Inf, 0)
+	}
+	return rate.NewLimiter(rate.Limit(perSecond), 1)
+}
+
+// geocodeKey rounds a coordinate to the configured precision, returning the
+// cache key and the rounded coordinate.
+func (s *Service) geocodeKey(lat, lng float64) (string, float64, float64) {
+	scale := math.Pow(10, float64(s.config.GeocodePrecision))
+	lat = math.Round(lat*scale) / scale
+	lng = math.Round(lng*scale) / scale
+	key := strconv.FormatFloat(lat, 'f', s.config.GeocodePrecision, 64) + "," +
+		strconv.FormatFloat(lng, 'f', s.config.GeocodePrecision, 64)
+	return key, lat, lng
+}
+
+// ReverseGeocode returns the address at a coordinate. Local providers are
+// asked directly. Network providers are read through the cache, and misses
+// wait on the rate limiter. Concurrent misses for the same rounded coordinate
+// share one provider call, detached from ctx and bounded by geocodeTimeout so
+// one caller giving up doesn't fail the others; each caller still stops
+// waiting once its own ctx is done.
+func (s *Service) ReverseGeocode(ctx context.Context, lat, lng float64) (*geocode.Address, error) {
+	if s.geocoder == nil {
+		return nil, ErrGeocodingDisabled
+	}
+	if local, ok := s.geocoder.(geocode.LocalProvider); ok && local.Local() {
+		return local.Reverse(ctx, lat, lng)
+	}
+
+	key, lat, lng := s.geocodeKey(lat, lng)
+
+	var entry models.GeocodeCacheEntry
+	err := s.db.WithContext(ctx).Where("cache_key = ? AND provider = ?", key, s.geocoder.Name()).First(&entry).Error
+	if err == nil && time.Since(entry.UpdatedAt) < s.config.GeocodeCacheTTL {
+		return addressFromCache(entry)
+	}
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("failed to read geocode cache: %w", err)
+	}
+
+	ch := s.geocodeGroup.DoChan(key, func() (interface{}, error) {
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), geocodeTimeout)
+		defer cancel()
+
+		if err := s.geocodeLimiter.Wait(ctx); err != nil {
+			return nil, err
+		}
+		addr, err := s.geocoder.Reverse(ctx, lat, lng)
+		if err != nil && !errors.Is(err, geocode.ErrNoResult) {
+			return nil, err
+		}
+
+		entry := models.GeocodeCacheEntry{Key: key, Lat: lat, Lng: lng, Provider: s.geocoder.Name()}
+		if addr != nil {
+			entry.Formatted, entry.City, entry.State = addr.Formatted, addr.City, addr.State
+			entry.Country, entry.CountryCode = addr.Country, addr.CountryCode
+		}
+		if err := s.db.WithContext(ctx).Clauses(clause.OnConflict{UpdateAll: true}).Create(&entry).Error; err != nil {
+			log.Printf("Failed to cache address for %s: %v", key, err)
+		}
+		return entry, nil
+	})
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case res := <-ch:
+		if res.Err != nil {
+			return nil, res.Err
+		}
+		return addressFromCache(res.Val.(models.GeocodeCacheEntry))
+	}
+}
+
+func addressFromCache(e models.GeocodeCacheEntry) (*geocode.Address, error) {
+	if e.Formatted == "" {
+		return nil, geocode.ErrNoResult
+	}
+	return &geocode.Address{
+		Formatted:   e.Formatted,
+		City:        e.City,
+		State:       e.State,
+		Country:     e.Country,
+		CountryCode: e.CountryCode,
+	}, nil
+}
+
+// WithAddresses returns a copy of devices with Address set from each latest
+// point. Devices whose lookup fails or doesn't finish before ctx is done are
+// returned without an address.
+func (s *Service) WithAddresses(ctx context.Context, devices []models.Device) []models.Device {
+	out := make([]models.Device, len(devices))
+	copy(out, devices)
+	if s.geocoder == nil {
+		return out
+	}
+
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, maxConcurrentGeocodes)
+	for i := range out {
+		p := out[i].LatestDevicePoint
+		if p.Lat == 0 && p.Lng == 0 {
+			continue
+		}
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(d *models.Device) {
+			defer func() { <-sem; wg.Done() }()
+			addr, err := s.ReverseGeocode(ctx, p.Lat, p.Lng)
+			if err != nil {
+				if !errors.Is(err, geocode.ErrNoResult) && ctx.Err() == nil {
+					log.Printf("Failed to geocode device %s: %v", d.DeviceID, err)
+				}
+				return
+			}
+			d.Address = addr.Formatted
+		}(&out[i])
+	}
+	wg.Wait()
+	return out
+}
@@ -0,0 +1,159 @@
+// services/geofence.go
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/alexbeattie/golangone/geo"
+	"github.com/alexbeattie/golangone/models"
+)
+
+// ErrInvalidGeofence wraps every geofence validation failure.
+var ErrInvalidGeofence = errors.New("invalid geofence")
+
+func invalidGeofence(format string, args ...interface{}) error {
+	return fmt.Errorf("%w: %s", ErrInvalidGeofence, fmt.Sprintf(format, args...))
+}
+
+// GeofenceShape validates g and decodes its geometry. GeoJSON positions are
+// [lng, lat].
+func GeofenceShape(g models.Geofence) (geo.Shape, error) {
+	switch g.Kind {
+	case models.GeofenceCircle:
+		if g.Geometry.Type != "Point" {
+			return nil, invalidGeofence("circle geometry must be a Point")
+		}
+		var pos []float64
+		if err := json.Unmarshal(g.Geometry.Coordinates, &pos); err != nil || len(pos) < 2 {
+			return nil, invalidGeofence("point coordinates must be [lng, lat]")
+		}
+		center := geo.Point{Lat: pos[1], Lng: pos[0]}
+		if err := geo.ValidatePoint(center); err != nil {
+			return nil, invalidGeofence("%v", err)
+		}
+		if g.RadiusMeters <= 0 {
+			return nil, invalidGeofence("radius_meters must be positive")
+		}
+		return geo.Circle{Center: center, Radius: g.RadiusMeters}, nil
+
+	case models.GeofencePolygon:
+		if g.Geometry.Type != "Polygon" {
+			return nil, invalidGeofence("polygon geometry must be a Polygon")
+		}
+		var rings [][][]float64
+		if err := json.Unmarshal(g.Geometry.Coordinates, &rings); err != nil {
+			return nil, invalidGeofence("polygon coordinates must be [[[lng, lat], ...]]")
+		}
+		pg := geo.Polygon{}
+		for _, ring := range rings {
+			points := make([]geo.Point, 0, len(ring))
+			for _, pos := range ring {
+				if len(pos) < 2 {
+					return nil, invalidGeofence("positions must be [lng, lat]")
+				}
+				points = append(points, geo.Point{Lat: pos[1], Lng: pos[0]})
+			}
+			pg.Rings = append(pg.Rings, points)
+		}
+		if err := pg.Validate(); err != nil {
+			return nil, invalidGeofence("%v", err)
+		}
+		return pg, nil
+
+	default:
+		return nil, invalidGeofence("kind must be %q or %q", models.GeofenceCircle, models.GeofencePolygon)
+	}
+}
+
+// prepareGeofence fills in the kind from the geometry type and validates g.
+func prepareGeofence(g *models.Geofence) error {
+	g.Name = strings.TrimSpace(g.Name)
+	if g.Name == "" {
+		return invalidGeofence("name is required")
+	}
+	if g.Kind == "" {
+		switch g.Geometry.Type {
+		case "Point":
+			g.Kind = models.GeofenceCircle
+		case "Polygon":
+			g.Kind = models.GeofencePolygon
+		}
+	}
+	_, err := GeofenceShape(*g)
+	return err
+}
+
+func (s *Service) ListGeofences() ([]models.Geofence, error) {
+	var geofences []models.Geofence
+	if err := s.db.Order("id").Find(&geofences).Error; err != nil {
+		return nil, err
+	}
+	return geofences, nil
+}
+
+func (s *Service) GetGeofence(id uint) (*models.Geofence, error) {
+	var g models.Geofence
+	if err := s.db.First(&g, id).Error; err != nil {
+		return nil, err
+	}
+	return &g, nil
+}
+
+func (s *Service) CreateGeofence(g *models.Geofence) error {
+	if err := prepareGeofence(g); err != nil {
+		return err
+	}
+	return s.db.Create(g).Error
+}
+
+// UpdateGeofence replaces the stored geofence with the same ID.
+func (s *Service) UpdateGeofence(g *models.Geofence) error {
+	existing, err := s.GetGeofence(g.ID)
+	if err != nil {
+		return err
+	}
+	if err := prepareGeofence(g); err != nil {
+		return err
+	}
+	g.CreatedAt = existing.CreatedAt
+	return s.db.Save(g).Error
+}
+
+func (s *Service) DeleteGeofence(id uint) error {
+	if _, err := s.GetGeofence(id); err != nil {
+		return err
+	}
+	return s.db.Delete(&models.Geofence{}, id).Error
+}
+
+// DevicesInGeofence returns the devices whose latest point lies inside the geofence.
+func (s *Service) DevicesInGeofence(id uint) ([]models.Device, error) {
+	g, err := s.GetGeofence(id)
+	if err != nil {
+		return nil, err
+	}
+	shape, err := GeofenceShape(*g)
+	if err != nil {
+		return nil, err
+	}
+
+	devices, err := s.FetchDevices()
+	if err != nil {
+		return nil, err
+	}
+
+	inside := []models.Device{}
+	for _, d := range devices {
+		p := d.LatestDevicePoint
+		if p.DevicePointID == "" {
+			continue
+		}
+		if shape.Contains(geo.Point{Lat: p.Lat, Lng: p.Lng}) {
+			inside = append(inside, d)
+		}
+	}
+	return inside, nil
+}
@@ -0,0 +1,204 @@
+// services/geofence_monitor.go
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/alexbeattie/golangone/geo"
+	"github.com/alexbeattie/golangone/models"
+)
+
+// metersPerHdop widens the hysteresis band for imprecise fixes.
+const metersPerHdop = 5.0
+
+type fenceKey struct {
+	deviceID   string
+	geofenceID uint
+}
+
+type fenceState struct {
+	inside    bool
+	enteredAt time.Time
+	dwelled   bool
+}
+
+// GeofenceMonitor emits enter, exit and dwell events as newly ingested points
+// cross geofence boundaries. A point must be at least margin meters (plus
+// metersPerHdop per unit of HDOP) past the boundary to change state, so GPS
+// jitter along an edge does not flap.
+type GeofenceMonitor struct {
+	service *Service
+	margin  float64
+	dwell   time.Duration
+
+	mu    sync.Mutex
+	state map[fenceKey]*fenceState
+}
+
+func NewGeofenceMonitor(service *Service, margin float64, dwell time.Duration) *GeofenceMonitor {
+	return &GeofenceMonitor{
+		service: service,
+		margin:  margin,
+		dwell:   dwell,
+		state:   make(map[fenceKey]*fenceState),
+	}
+}
+
+// DevicesPolled implements PollObserver.
+func (m *GeofenceMonitor) DevicesPolled(ctx context.Context, devices []models.Device, stored []models.StoredDevicePoint) {
+	if len(stored) == 0 {
+		return
+	}
+
+	geofences, err := m.service.ListGeofences()
+	if err != nil {
+		log.Printf("Geofence monitor: %v", err)
+		return
+	}
+
+	live := make(map[uint]bool, len(geofences))
+	shapes := make(map[uint]geo.Shape, len(geofences))
+	for _, g := range geofences {
+		live[g.ID] = true
+		shape, err := GeofenceShape(g)
+		if err != nil {
+			continue
+		}
+		shapes[g.ID] = shape
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.prune(ctx, live, stored)
+	for _, p := range stored {
+		for id, shape := range shapes {
+			if err := m.check(ctx, p, id, shape); err != nil {
+				log.Printf("Geofence monitor: %v", err)
+			}
+		}
+	}
+}
+
+func (m *GeofenceMonitor) check(ctx context.Context, p models.StoredDevicePoint, geofenceID uint, shape geo.Shape) error {
+	key := fenceKey{deviceID: p.DeviceID, geofenceID: geofenceID}
+	st, err := m.load(key)
+	if err != nil {
+		return err
+	}
+
+	band := m.margin + p.Hdop*metersPerHdop
+	dist := shape.BoundaryDistance(geo.Point{Lat: p.Lat, Lng: p.Lng})
+
+	// State only changes once the event is stored, so a failed write is
+	// retried on the next point instead of the transition being lost.
+	switch {
+	case !st.inside && dist < -band:
+		if err := m.emit(ctx, p, geofenceID, models.EventEnter, nil); err != nil {
+			return err
+		}
+		st.inside, st.enteredAt, st.dwelled = true, p.DtTracker, false
+
+	case st.inside && dist > band:
+		if err := m.emit(ctx, p, geofenceID, models.EventExit, map[string]interface{}{
+			"dwell_seconds": p.DtTracker.Sub(st.enteredAt).Seconds(),
+		}); err != nil {
+			return err
+		}
+		st.inside = false
+
+	case st.inside && !st.dwelled && m.dwell > 0 && p.DtTracker.Sub(st.enteredAt) >= m.dwell:
+		if err := m.emit(ctx, p, geofenceID, models.EventDwell, map[string]interface{}{
+			"dwell_seconds": p.DtTracker.Sub(st.enteredAt).Seconds(),
+		}); err != nil {
+			return err
+		}
+		st.dwelled = true
+	}
+	return nil
+}
+
+// prune drops the state of geofences that no longer exist, first emitting an
+// exit for each device still inside one. The exit is placed at the device's
+// newest point of this poll, or at the current time if it has none.
+func (m *GeofenceMonitor) prune(ctx context.Context, live map[uint]bool, stored []models.StoredDevicePoint) {
+	latest := make(map[string]models.StoredDevicePoint, len(stored))
+	for _, p := range stored {
+		if cur, ok := latest[p.DeviceID]; !ok || p.DtTracker.After(cur.DtTracker) {
+			latest[p.DeviceID] = p
+		}
+	}
+
+	for key, st := range m.state {
+		if live[key.geofenceID] {
+			continue
+		}
+		if st.inside {
+			p, ok := latest[key.deviceID]
+			if !ok {
+				p = models.StoredDevicePoint{DeviceID: key.deviceID, DtTracker: time.Now().UTC()}
+			}
+			err := m.emit(ctx, p, key.geofenceID, models.EventExit, map[string]interface{}{
+				"dwell_seconds":    p.DtTracker.Sub(st.enteredAt).Seconds(),
+				"geofence_deleted": true,
+			})
+			if err != nil {
+				// Keep the state so the exit is retried next poll.
+				log.Printf("Geofence monitor: %v", err)
+				continue
+			}
+		}
+		delete(m.state, key)
+	}
+}
+
+// load returns the state for key, restoring it from the last stored event the
+// first time the pair is seen so restarts do not re-emit enters.
+func (m *GeofenceMonitor) load(key fenceKey) (*fenceState, error) {
+	if st, ok := m.state[key]; ok {
+		return st, nil
+	}
+
+	st := &fenceState{}
+	var last models.Event
+	err := m.service.db.
+		Where("type = ? AND device_id = ? AND geofence_id = ?", models.EventTypeGeofence, key.deviceID, key.geofenceID).
+		Order("occurred_at DESC, id DESC").
+		First(&last).Error
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+	case err != nil:
+		return nil, err
+	case last.Subtype == models.EventDwell:
+		st.inside, st.dwelled = true, true
+		st.enteredAt = last.OccurredAt
+		if secs, ok := last.Data["dwell_seconds"].(float64); ok {
+			st.enteredAt = last.OccurredAt.Add(-time.Duration(secs * float64(time.Second)))
+		}
+	case last.Subtype == models.EventEnter:
+		st.inside, st.enteredAt = true, last.OccurredAt
+	}
+
+	m.state[key] = st
+	return st, nil
+}
+
+func (m *GeofenceMonitor) emit(ctx context.Context, p models.StoredDevicePoint, geofenceID uint, subtype string, data map[string]interface{}) error {
+	id := geofenceID
+	return m.service.recordEvent(ctx, &models.Event{
+		Type:       models.EventTypeGeofence,
+		Subtype:    subtype,
+		DeviceID:   p.DeviceID,
+		GeofenceID: &id,
+		OccurredAt: p.DtTracker,
+		Lat:        p.Lat,
+		Lng:        p.Lng,
+		Data:       data,
+	})
+}
@@ -0,0 +1,112 @@
+// services/harsh.go
+package services
+
+import (
+	"context"
+	"log"
+	"strings"
+
+	"github.com/alexbeattie/golangone/models"
+)
+
+// Motion log thresholds in g used when a device reports forces but no
+// explicit harsh event list.
+const (
+	harshBrakingG      = 0.35
+	harshAccelerationG = 0.30
+	harshCorneringG    = 0.35
+)
+
+// HarshEventDetector stores harsh braking, acceleration and cornering events
+// found on newly ingested points.
+type HarshEventDetector struct {
+	service *Service
+}
+
+func NewHarshEventDetector(service *Service) *HarshEventDetector {
+	return &HarshEventDetector{service: service}
+}
+
+// DevicesPolled implements PollObserver.
+func (d *HarshEventDetector) DevicesPolled(ctx context.Context, devices []models.Device, stored []models.StoredDevicePoint) {
+	fresh := make(map[string]models.StoredDevicePoint, len(stored))
+	for _, p := range stored {
+		fresh[p.DevicePointID] = p
+	}
+
+	for _, device := range devices {
+		point := device.LatestDevicePoint
+		sp, ok := fresh[point.DevicePointID]
+		if !ok {
+			continue
+		}
+
+		for _, h := range harshEvents(point.DevicePointDetail) {
+			ev := &models.Event{
+				Type:       models.EventTypeHarsh,
+				Subtype:    h.subtype,
+				DeviceID:   sp.DeviceID,
+				OccurredAt: sp.DtTracker,
+				Lat:        sp.Lat,
+				Lng:        sp.Lng,
+				Data: map[string]interface{}{
+					"source":    h.source,
+					"force_g":   h.forceG,
+					"speed_kph": sp.Speed,
+				},
+			}
+			if err := d.service.recordEvent(ctx, ev); err != nil {
+				log.Printf("Harsh event detector: %v", err)
+			}
+		}
+	}
+}
+
+type harshEvent struct {
+	subtype string
+	source  string
+	forceG  float64
+}
+
+// harshEvents prefers the device's own event list and falls back to
+// thresholding the motion log forces.
+func harshEvents(detail models.DevicePointDetail) []harshEvent {
+	var events []harshEvent
+	for _, h := range detail.HeventList.Events {
+		events = append(events, harshEvent{
+			subtype: classifyHarsh(h.HeventType),
+			source:  h.HeventType,
+			forceG:  models.ForceG(h.Force),
+		})
+	}
+	if len(events) > 0 {
+		return events
+	}
+
+	ml := detail.MotionLog
+	if g := models.ForceG(&ml.MaxDeceleratingForce); g >= harshBrakingG {
+		events = append(events, harshEvent{models.HarshBraking, "motion_log", g})
+	}
+	if g := models.ForceG(&ml.MaxAcceleratingForce); g >= harshAccelerationG {
+		events = append(events, harshEvent{models.HarshAcceleration, "motion_log", g})
+	}
+	turn := max(models.ForceG(&ml.MaxLeftTurnForce), models.ForceG(&ml.MaxRightTurnForce))
+	if turn >= harshCorneringG {
+		events = append(events, harshEvent{models.HarshCornering, "motion_log", turn})
+	}
+	return events
+}
+
+func classifyHarsh(heventType string) string {
+	t := strings.ToLower(heventType)
+	switch {
+	case strings.Contains(t, "brak"), strings.Contains(t, "decel"):
+		return models.HarshBraking
+	case strings.Contains(t, "accel"):
+		return models.HarshAcceleration
+	case strings.Contains(t, "corner"), strings.Contains(t, "turn"):
+		return models.HarshCornering
+	default:
+		return models.HarshOther
+	}
+}
@@ -0,0 +1,122 @@
+// services/history.go
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/alexbeattie/golangone/geo"
+	"github.com/alexbeattie/golangone/models"
+)
+
+const (
+	DefaultHistoryLimit = 1000
+	MaxHistoryLimit     = 5000
+
+	historyBatchSize = 500
+)
+
+// ErrInvalidCursor is returned when a history cursor does not resolve to a
+// stored point of the requested device.
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// HistoryQuery selects stored points for one device. Interval and MinDistance
+// downsample the result: a point is kept only if it is at least Interval after
+// and MinDistance meters away from the previously kept point.
+type HistoryQuery struct {
+	DeviceID    string
+	From        time.Time
+	To          time.Time
+	Cursor      string
+	Limit       int
+	Interval    time.Duration
+	MinDistance float64
+}
+
+// HistoryPage is one page of history. NextCursor is empty on the last page.
+type HistoryPage struct {
+	Points     []models.StoredDevicePoint `json:"points"`
+	NextCursor string                     `json:"next_cursor,omitempty"`
+}
+
+// QueryHistory returns stored points in [From, To] ordered by tracker time.
+// The cursor is the ID of the last point of the previous page; because that
+// point is always a kept one, downsampling carries over between pages.
+func (s *Service) QueryHistory(q HistoryQuery) (*HistoryPage, error) {
+	if q.Limit <= 0 {
+		q.Limit = DefaultHistoryLimit
+	}
+	if q.Limit > MaxHistoryLimit {
+		q.Limit = MaxHistoryLimit
+	}
+
+	var last *models.StoredDevicePoint
+	if q.Cursor != "" {
+		id, err := strconv.ParseUint(q.Cursor, 10, 64)
+		if err != nil {
+			return nil, ErrInvalidCursor
+		}
+		var p models.StoredDevicePoint
+		err = s.db.Where("device_id = ?", q.DeviceID).First(&p, id).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidCursor
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve cursor: %w", err)
+		}
+		last = &p
+	}
+
+	page := &HistoryPage{Points: []models.StoredDevicePoint{}}
+	scanFrom := last
+	for {
+		query := s.db.Where("device_id = ? AND dt_tracker BETWEEN ? AND ?", q.DeviceID, q.From, q.To)
+		if scanFrom != nil {
+			query = query.Where("(dt_tracker, id) > (?, ?)", scanFrom.DtTracker, scanFrom.ID)
+		}
+
+		var batch []models.StoredDevicePoint
+		if err := query.Order("dt_tracker, id").Limit(historyBatchSize).Find(&batch).Error; err != nil {
+			return nil, fmt.Errorf("failed to query history: %w", err)
+		}
+
+		for i := range batch {
+			p := batch[i]
+			if !keepPoint(last, p, q) {
+				continue
+			}
+			page.Points = append(page.Points, p)
+			last = &page.Points[len(page.Points)-1]
+
+			// Look one kept point past the limit so a page that ends exactly
+			// at the last point doesn't advertise an empty next page.
+			if len(page.Points) > q.Limit {
+				page.Points = page.Points[:q.Limit]
+				page.NextCursor = strconv.FormatUint(uint64(page.Points[q.Limit-1].ID), 10)
+				return page, nil
+			}
+		}
+
+		if len(batch) < historyBatchSize {
+			return page, nil
+		}
+		scanFrom = &batch[len(batch)-1]
+	}
+}
+
+func keepPoint(last *models.StoredDevicePoint, p models.StoredDevicePoint, q HistoryQuery) bool {
+	if last == nil {
+		return true
+	}
+	if q.Interval > 0 && p.DtTracker.Sub(last.DtTracker) < q.Interval {
+		return false
+	}
+	if q.MinDistance > 0 && geo.DistanceMeters(last.Lat, last.Lng, p.Lat, p.Lng) < q.MinDistance {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,175 @@
+// services/ingest.go
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"gorm.io/gorm/clause"
+
+	"github.com/alexbeattie/golangone/models"
+)
+
+// PollObserver is notified after every successful ingestion poll with the
+// full device list and the points that were newly stored by that poll.
+type PollObserver interface {
+	DevicesPolled(ctx context.Context, devices []models.Device, stored []models.StoredDevicePoint)
+}
+
+// Ingestor polls the device list and stores each device's latest point so
+// position history is kept locally.
+type Ingestor struct {
+	service   *Service
+	interval  time.Duration
+	observers []PollObserver
+	// lastSeen maps device ID to the last stored point ID/sequence, so the
+	// unchanged latest point of an idle device is skipped without a DB write.
+	lastSeen map[string]string
+}
+
+func NewIngestor(service *Service, interval time.Duration, observers ...PollObserver) *Ingestor {
+	return &Ingestor{
+		service:   service,
+		interval:  interval,
+		observers: observers,
+		lastSeen:  make(map[string]string),
+	}
+}
+
+// Run polls until ctx is cancelled. A non-positive interval disables ingestion.
+func (i *Ingestor) Run(ctx context.Context) {
+	if i.interval <= 0 {
+		log.Printf("Ingestion disabled")
+		return
+	}
+
+	ticker := time.NewTicker(i.interval)
+	defer ticker.Stop()
+
+	for {
+		if n, err := i.Poll(ctx); err != nil {
+			log.Printf("Ingestion poll failed: %v", err)
+		} else if n > 0 {
+			log.Printf("Ingested %d device points", n)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Poll fetches the device list once and stores any new points, returning how
+// many were inserted. Points that fail to store are logged and skipped;
+// observers still see the rest.
+func (i *Ingestor) Poll(ctx context.Context) (int, error) {
+	devices, err := i.service.RefreshDevices()
+	if err != nil {
+		return 0, err
+	}
+
+	if err := i.storeDeviceRecords(ctx, devices); err != nil {
+		return 0, err
+	}
+
+	var stored []models.StoredDevicePoint
+	for _, device := range devices {
+		point, ok := storedPointFromDevice(device)
+		if !ok {
+			continue
+		}
+
+		key := point.DevicePointID + "|" + point.Sequence
+		if i.lastSeen[device.DeviceID] == key {
+			continue
+		}
+
+		result := i.service.db.WithContext(ctx).
+			Clauses(clause.OnConflict{DoNothing: true}).
+			Create(&point)
+		if result.Error != nil {
+			// Leave lastSeen alone so the point is retried next poll, and
+			// keep going so one bad row doesn't stall every other device.
+			log.Printf("Ingest: failed to store point for device %s: %v", device.DeviceID, result.Error)
+			continue
+		}
+
+		i.lastSeen[device.DeviceID] = key
+		if result.RowsAffected > 0 {
+			stored = append(stored, point)
+		}
+	}
+
+	for _, o := range i.observers {
+		o.DevicesPolled(ctx, devices, stored)
+	}
+
+	return len(stored), nil
+}
+
+// storeDeviceRecords upserts the identity and settings of every device.
+func (i *Ingestor) storeDeviceRecords(ctx context.Context, devices []models.Device) error {
+	if len(devices) == 0 {
+		return nil
+	}
+
+	records := make([]models.DeviceRecord, 0, len(devices))
+	for _, d := range devices {
+		records = append(records, models.DeviceRecord{
+			DeviceID:    d.DeviceID,
+			DisplayName: d.DisplayName,
+			Settings:    d.Settings,
+		})
+	}
+
+	err := i.service.db.WithContext(ctx).
+		Clauses(clause.OnConflict{UpdateAll: true}).
+		Create(&records).Error
+	if err != nil {
+		return fmt.Errorf("failed to store device records: %w", err)
+	}
+	return nil
+}
+
+// storedPointFromDevice converts a device's latest point into its stored form.
+// It reports false for devices that have not reported a usable point.
+func storedPointFromDevice(device models.Device) (models.StoredDevicePoint, bool) {
+	p := device.LatestDevicePoint
+	if p.DevicePointID == "" {
+		return models.StoredDevicePoint{}, false
+	}
+
+	dtTracker, err := time.Parse(time.RFC3339, p.DtTracker)
+	if err != nil {
+		return models.StoredDevicePoint{}, false
+	}
+	dtServer, _ := time.Parse(time.RFC3339, p.DtServer)
+	posted := p.PostedSpeed()
+
+	return models.StoredDevicePoint{
+		DeviceID:      device.DeviceID,
+		DevicePointID: p.DevicePointID,
+		Sequence:      p.Sequence,
+		DtTracker:     dtTracker,
+		DtServer:      dtServer,
+		Lat:           p.Lat,
+		Lng:           p.Lng,
+		Speed:         p.Speed,
+		Angle:         p.Angle,
+		DriveStatus:   p.DeviceState.DriveStatus,
+		Odometer:      p.DeviceState.Odometer.Value,
+		OdometerUnit:  p.DeviceState.Odometer.Unit,
+		ExternalVolt:  p.DevicePointDetail.ExternalVolt,
+		Hdop:          p.DevicePointDetail.Hdop,
+		NumSatellites: p.DevicePointDetail.NumSatellites,
+		Acc:           p.DevicePointDetail.Acc,
+
+		PostedSpeedLimit: posted.LimitMph,
+		MphOverPosted:    posted.MphOver,
+		PctOverPosted:    posted.PctOver,
+	}, true
+}
@@ -0,0 +1,180 @@
+// services/onestepgps.go
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/alexbeattie/golangone/config"
+	"github.com/alexbeattie/golangone/models"
+)
+
+// maxErrorBody caps how much of an upstream error body is kept for logs.
+const maxErrorBody = 512
+
+// OneStepGPSClient talks to the OneStepGPS public v3 API.
+type OneStepGPSClient struct {
+	baseURL string
+	apiKey  string
+	client  *http.Client
+	retry   RetryPolicy
+	breaker *CircuitBreaker
+}
+
+func NewOneStepGPSClient(cfg *config.Config) *OneStepGPSClient {
+	baseURL := cfg.OneStepGPSBaseURL
+	if baseURL == "" {
+		baseURL = config.DefaultOneStepGPSBaseURL
+	}
+
+	return &OneStepGPSClient{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		apiKey:  cfg.OneStepGPSAPIKey,
+		client:  &http.Client{Timeout: 10 * time.Second},
+		retry: RetryPolicy{
+			MaxRetries: cfg.UpstreamMaxRetries,
+			BaseDelay:  cfg.UpstreamRetryBaseDelay,
+			MaxDelay:   cfg.UpstreamRetryMaxDelay,
+		},
+		breaker: NewCircuitBreaker(cfg.BreakerFailureThreshold, cfg.BreakerCooldown),
+	}
+}
+
+// BreakerState reports the circuit breaker guarding upstream calls.
+func (c *OneStepGPSClient) BreakerState() BreakerSnapshot {
+	return c.breaker.Snapshot()
+}
+
+// endpoint builds the full URL for path with the API key appended to query.
+func (c *OneStepGPSClient) endpoint(path string, query url.Values) string {
+	if query == nil {
+		query = url.Values{}
+	}
+	query.Set("api-key", c.apiKey)
+	return fmt.Sprintf("%s/%s?%s", c.baseURL, strings.TrimLeft(path, "/"), query.Encode())
+}
+
+// get fetches path and decodes the JSON body into out. Transient failures are
+// retried per the client's RetryPolicy, honoring Retry-After, until ctx is
+// done. The circuit breaker sees one outcome per call, however many attempts
+// it took. Transport failures, non-2xx statuses and undecodable bodies are
+// all returned as *UpstreamError.
+func (c *OneStepGPSClient) get(ctx context.Context, op, path string, query url.Values, out interface{}) error {
+	if err := c.breaker.Allow(); err != nil {
+		return &UpstreamError{Kind: ErrUpstreamUnavailable, Op: op, Err: err}
+	}
+
+	err := c.getWithRetry(ctx, op, c.endpoint(path, query), out)
+	switch {
+	case ctx.Err() != nil:
+		// The caller gave up; that says nothing about the upstream.
+		c.breaker.Release()
+	case errors.Is(err, ErrUpstreamUnavailable):
+		c.breaker.Failure()
+	default:
+		c.breaker.Success()
+	}
+	return err
+}
+
+func (c *OneStepGPSClient) getWithRetry(ctx context.Context, op, target string, out interface{}) error {
+	for attempt := 1; ; attempt++ {
+		err := c.getOnce(ctx, op, target, out)
+		if err == nil || !retryable(err) || attempt > c.retry.MaxRetries {
+			return err
+		}
+
+		wait := c.retry.backoff(attempt)
+		var upErr *UpstreamError
+		if errors.As(err, &upErr) && upErr.RetryAfter > 0 {
+			if upErr.RetryAfter > c.retry.MaxDelay {
+				return err
+			}
+			wait = upErr.RetryAfter
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return &UpstreamError{Kind: ErrUpstreamUnavailable, Op: op, Err: ctx.Err()}
+		case <-timer.C:
+		}
+	}
+}
+
+func (c *OneStepGPSClient) getOnce(ctx context.Context, op, target string, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return &UpstreamError{Kind: ErrUpstreamUnavailable, Op: op, Err: redactURL(err)}
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return &UpstreamError{Kind: ErrUpstreamUnavailable, Op: op, Err: redactURL(err)}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+		return &UpstreamError{
+			Kind:       kindForStatus(resp.StatusCode),
+			Op:         op,
+			StatusCode: resp.StatusCode,
+			Body:       strings.TrimSpace(string(body)),
+			RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After")),
+		}
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return &UpstreamError{Kind: ErrUpstreamMalformed, Op: op, StatusCode: resp.StatusCode, Err: err}
+	}
+	return nil
+}
+
+func (c *OneStepGPSClient) FetchDevices(ctx context.Context) ([]models.Device, error) {
+	var response models.APIResponse
+	if err := c.get(ctx, "fetch devices", "device", url.Values{"latest_point": {"true"}}, &response); err != nil {
+		return nil, err
+	}
+
+	return response.ResultList, nil
+}
+
+func (c *OneStepGPSClient) FetchDeviceInfo(ctx context.Context, params map[string]string) (*models.DeviceInfoResponse, error) {
+	query := url.Values{"lat_lng": {"1"}}
+	for k, v := range params {
+		query.Set(k, v)
+	}
+
+	var response models.DeviceInfoResponse
+	if err := c.get(ctx, "fetch device info", "device-info", query, &response); err != nil {
+		return nil, err
+	}
+
+	return &response, nil
+}
+
+func (c *OneStepGPSClient) FetchDriveStopRoute(ctx context.Context, deviceID string, fromTime, toTime time.Time, stopDuration string) (*models.DriveStopResponse, error) {
+	query := url.Values{
+		"device_id":         {deviceID},
+		"dt_tracker_from":   {fromTime.Format(time.RFC3339)},
+		"dt_tracker_to":     {toTime.Format(time.RFC3339)},
+		"stop_duration":     {stopDuration},
+		"return_points":     {"true"},
+		"max_return_points": {"999"},
+	}
+
+	var response models.DriveStopResponse
+	if err := c.get(ctx, "fetch drive-stop route", "route/drive-stop", query, &response); err != nil {
+		return nil, err
+	}
+
+	return &response, nil
+}
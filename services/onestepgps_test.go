@@ -0,0 +1,216 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/alexbeattie/golangone/config"
+)
+
+// newTestClient returns a client for a server running handler, retrying
+// maxRetries times with millisecond backoff.
+func newTestClient(t *testing.T, maxRetries, breakerThreshold int, handler http.HandlerFunc) (*OneStepGPSClient, *int32) {
+	t.Helper()
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		handler(w, r)
+	}))
+	t.Cleanup(srv.Close)
+
+	client := NewOneStepGPSClient(&config.Config{
+		OneStepGPSBaseURL:       srv.URL + "/",
+		OneStepGPSAPIKey:        "secret-key",
+		UpstreamMaxRetries:      maxRetries,
+		UpstreamRetryBaseDelay:  time.Millisecond,
+		UpstreamRetryMaxDelay:   5 * time.Millisecond,
+		BreakerFailureThreshold: breakerThreshold,
+		BreakerCooldown:         time.Minute,
+	})
+	return client, &calls
+}
+
+func TestOneStepGPSFetchDevices(t *testing.T) {
+	client, _ := newTestClient(t, 0, 5, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/device" {
+			t.Errorf("path = %q, want /device", r.URL.Path)
+		}
+		if got := r.URL.Query().Get("api-key"); got != "secret-key" {
+			t.Errorf("api-key = %q", got)
+		}
+		if got := r.URL.Query().Get("latest_point"); got != "true" {
+			t.Errorf("latest_point = %q", got)
+		}
+		w.Write([]byte(`{"result_list": [{"device_id": "d1", "display_name": "Truck 1"}, {"device_id": "d2"}]}`))
+	})
+
+	devices, err := client.FetchDevices(context.Background())
+	if err != nil {
+		t.Fatalf("FetchDevices: %v", err)
+	}
+	if len(devices) != 2 || devices[0].DeviceID != "d1" || devices[0].DisplayName != "Truck 1" {
+		t.Fatalf("devices = %+v", devices)
+	}
+}
+
+func TestOneStepGPSFetchDriveStopRoute(t *testing.T) {
+	from := time.Date(2026, 3, 2, 8, 0, 0, 0, time.UTC)
+	client, _ := newTestClient(t, 0, 5, func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if r.URL.Path != "/route/drive-stop" || q.Get("device_id") != "d1" || q.Get("dt_tracker_from") != from.Format(time.RFC3339) {
+			t.Errorf("unexpected request %s", r.URL.Path+"?"+r.URL.RawQuery)
+		}
+		w.Write([]byte(`{"drive_stop_list": [{"type": "drive"}]}`))
+	})
+
+	route, err := client.FetchDriveStopRoute(context.Background(), "d1", from, from.Add(time.Hour), "5m")
+	if err != nil {
+		t.Fatalf("FetchDriveStopRoute: %v", err)
+	}
+	if len(route.DriveStopList) != 1 || route.DriveStopList[0].Type != "drive" {
+		t.Fatalf("route = %+v", route)
+	}
+}
+
+func TestOneStepGPSErrors(t *testing.T) {
+	tests := []struct {
+		name      string
+		status    int
+		header    map[string]string
+		body      string
+		wantKind  error
+		wantCalls int32
+	}{
+		{name: "unauthorized", status: http.StatusUnauthorized, body: "bad key", wantKind: ErrUpstreamAuth, wantCalls: 1},
+		{name: "rejected", status: http.StatusBadRequest, wantKind: ErrUpstreamRejected, wantCalls: 1},
+		{name: "unavailable is retried", status: http.StatusServiceUnavailable, wantKind: ErrUpstreamUnavailable, wantCalls: 3},
+		{name: "rate limited is retried", status: http.StatusTooManyRequests, wantKind: ErrUpstreamRateLimited, wantCalls: 3},
+		{
+			name:      "retry-after beyond the max delay is not waited for",
+			status:    http.StatusTooManyRequests,
+			header:    map[string]string{"Retry-After": "120"},
+			wantKind:  ErrUpstreamRateLimited,
+			wantCalls: 1,
+		},
+		{name: "malformed body", status: http.StatusOK, body: "{not json", wantKind: ErrUpstreamMalformed, wantCalls: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, calls := newTestClient(t, 2, 5, func(w http.ResponseWriter, r *http.Request) {
+				for k, v := range tt.header {
+					w.Header().Set(k, v)
+				}
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			})
+
+			_, err := client.FetchDevices(context.Background())
+			if !errors.Is(err, tt.wantKind) {
+				t.Fatalf("err = %v, want %v", err, tt.wantKind)
+			}
+			var upErr *UpstreamError
+			if !errors.As(err, &upErr) || upErr.StatusCode != tt.status {
+				t.Fatalf("err = %#v, want *UpstreamError with status %d", err, tt.status)
+			}
+			if got := atomic.LoadInt32(calls); got != tt.wantCalls {
+				t.Errorf("upstream calls = %d, want %d", got, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestOneStepGPSRetryThenSuccess(t *testing.T) {
+	var n int32
+	client, calls := newTestClient(t, 2, 5, func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&n, 1) == 1 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.Write([]byte(`{"result_list": []}`))
+	})
+
+	if _, err := client.FetchDevices(context.Background()); err != nil {
+		t.Fatalf("FetchDevices: %v", err)
+	}
+	if got := atomic.LoadInt32(calls); got != 2 {
+		t.Errorf("upstream calls = %d, want 2", got)
+	}
+	if snap := client.BreakerState(); snap.State != BreakerClosed || snap.ConsecutiveFailures != 0 {
+		t.Errorf("breaker = %+v, want closed with no failures", snap)
+	}
+}
+
+func TestOneStepGPSBreaker(t *testing.T) {
+	client, calls := newTestClient(t, 2, 2, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+
+	// Retries within one call count as a single failure.
+	client.FetchDevices(context.Background())
+	if snap := client.BreakerState(); snap.State != BreakerClosed || snap.ConsecutiveFailures != 1 {
+		t.Fatalf("after one call breaker = %+v, want closed with 1 failure", snap)
+	}
+
+	client.FetchDevices(context.Background())
+	if snap := client.BreakerState(); snap.State != BreakerOpen {
+		t.Fatalf("after two calls breaker = %+v, want open", snap)
+	}
+
+	before := atomic.LoadInt32(calls)
+	_, err := client.FetchDevices(context.Background())
+	if !errors.Is(err, ErrCircuitOpen) || !errors.Is(err, ErrUpstreamUnavailable) {
+		t.Fatalf("err = %v, want open circuit", err)
+	}
+	if atomic.LoadInt32(calls) != before {
+		t.Error("open breaker let a call through")
+	}
+}
+
+func TestOneStepGPSCancelDuringBackoff(t *testing.T) {
+	client, _ := newTestClient(t, 5, 1, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "1")
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+	client.retry.MaxDelay = time.Minute
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := client.FetchDevices(ctx)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("err = %v, want deadline exceeded", err)
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("returned after %s, want promptly after cancellation", elapsed)
+	}
+	// A caller giving up is not an upstream failure.
+	if snap := client.BreakerState(); snap.State != BreakerClosed || snap.ConsecutiveFailures != 0 {
+		t.Errorf("breaker = %+v, want closed with no failures", snap)
+	}
+}
+
+func TestOneStepGPSErrorOmitsAPIKey(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	srv.Close() // nothing listens, so the transport fails
+
+	client := NewOneStepGPSClient(&config.Config{
+		OneStepGPSBaseURL:       srv.URL,
+		OneStepGPSAPIKey:        "secret-key",
+		BreakerFailureThreshold: 5,
+	})
+	_, err := client.FetchDevices(context.Background())
+	if !errors.Is(err, ErrUpstreamUnavailable) {
+		t.Fatalf("err = %v, want ErrUpstreamUnavailable", err)
+	}
+	if strings.Contains(err.Error(), "secret-key") {
+		t.Errorf("error leaks the API key: %v", err)
+	}
+}
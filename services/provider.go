@@ -0,0 +1,83 @@
+// services/provider.go
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/alexbeattie/golangone/models"
+)
+
+// TrackingProvider is the upstream source of device, device-info and route data.
+// The OneStepGPS client is the production implementation; FileProvider serves
+// recorded payloads so the server can run against a local fixture.
+type TrackingProvider interface {
+	FetchDevices(ctx context.Context) ([]models.Device, error)
+	FetchDeviceInfo(ctx context.Context, params map[string]string) (*models.DeviceInfoResponse, error)
+	FetchDriveStopRoute(ctx context.Context, deviceID string, fromTime, toTime time.Time, stopDuration string) (*models.DriveStopResponse, error)
+}
+
+// breakerReporter is implemented by providers guarded by a CircuitBreaker.
+type breakerReporter interface {
+	BreakerState() BreakerSnapshot
+}
+
+// FileProvider serves the device list from a JSON file on disk. Both the bare
+// array format (devices.json) and the API envelope format (all.json) are accepted.
+type FileProvider struct {
+	path string
+}
+
+func NewFileProvider(path string) *FileProvider {
+	return &FileProvider{path: path}
+}
+
+func (p *FileProvider) FetchDevices(ctx context.Context) ([]models.Device, error) {
+	data, err := os.ReadFile(p.path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read fixture: %w", err)
+	}
+
+	if strings.HasPrefix(strings.TrimSpace(string(data)), "[") {
+		var devices []models.Device
+		if err := json.Unmarshal(data, &devices); err != nil {
+			return nil, fmt.Errorf("failed to decode fixture: %w", err)
+		}
+		return devices, nil
+	}
+
+	var response models.APIResponse
+	if err := json.Unmarshal(data, &response); err != nil {
+		return nil, fmt.Errorf("failed to decode fixture: %w", err)
+	}
+	return response.ResultList, nil
+}
+
+func (p *FileProvider) FetchDeviceInfo(ctx context.Context, params map[string]string) (*models.DeviceInfoResponse, error) {
+	devices, err := p.FetchDevices(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &models.DeviceInfoResponse{}
+	for _, d := range devices {
+		response.ResultList = append(response.ResultList, models.DeviceInfo{
+			DeviceID:    d.DeviceID,
+			DisplayName: d.DisplayName,
+		})
+	}
+	return response, nil
+}
+
+// FetchDriveStopRoute returns an empty route; fixtures carry no route history.
+func (p *FileProvider) FetchDriveStopRoute(ctx context.Context, deviceID string, fromTime, toTime time.Time, stopDuration string) (*models.DriveStopResponse, error) {
+	return &models.DriveStopResponse{
+		TimeFrom:      fromTime.Format(time.RFC3339),
+		TimeTo:        toTime.Format(time.RFC3339),
+		DriveStopList: []models.DriveStopPoint{},
+	}, nil
+}
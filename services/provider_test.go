@@ -0,0 +1,100 @@
+package services
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestFileProvider(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantIDs []string
+		wantErr bool
+	}{
+		{
+			name:    "bare array",
+			content: `[{"device_id": "d1", "display_name": "Truck 1"}, {"device_id": "d2"}]`,
+			wantIDs: []string{"d1", "d2"},
+		},
+		{
+			name:    "API envelope",
+			content: ` {"result_list": [{"device_id": "d3", "display_name": "Van"}]}`,
+			wantIDs: []string{"d3"},
+		},
+		{name: "invalid JSON", content: `{"result_list": [`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "devices.json")
+			if err := os.WriteFile(path, []byte(tt.content), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			p := NewFileProvider(path)
+
+			devices, err := p.FetchDevices(context.Background())
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("FetchDevices succeeded, want error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("FetchDevices: %v", err)
+			}
+			if len(devices) != len(tt.wantIDs) {
+				t.Fatalf("got %d devices, want %d", len(devices), len(tt.wantIDs))
+			}
+			for i, id := range tt.wantIDs {
+				if devices[i].DeviceID != id {
+					t.Errorf("device %d = %q, want %q", i, devices[i].DeviceID, id)
+				}
+			}
+
+			info, err := p.FetchDeviceInfo(context.Background(), nil)
+			if err != nil {
+				t.Fatalf("FetchDeviceInfo: %v", err)
+			}
+			if len(info.ResultList) != len(devices) || info.ResultList[0].DisplayName != devices[0].DisplayName {
+				t.Errorf("device info = %+v, want one entry per device", info.ResultList)
+			}
+		})
+	}
+}
+
+func TestFileProviderMissingFile(t *testing.T) {
+	p := NewFileProvider(filepath.Join(t.TempDir(), "missing.json"))
+	if _, err := p.FetchDevices(context.Background()); err == nil {
+		t.Fatal("FetchDevices succeeded, want error")
+	}
+}
+
+func TestFileProviderRouteIsEmpty(t *testing.T) {
+	from := time.Date(2026, 3, 2, 8, 0, 0, 0, time.UTC)
+	route, err := NewFileProvider("unused").FetchDriveStopRoute(context.Background(), "d1", from, from.Add(time.Hour), "5m")
+	if err != nil {
+		t.Fatalf("FetchDriveStopRoute: %v", err)
+	}
+	if route.DriveStopList == nil || len(route.DriveStopList) != 0 || route.TimeFrom != from.Format(time.RFC3339) {
+		t.Errorf("route = %+v, want an empty list for the window", route)
+	}
+}
+
+// TestFileProviderFixtures loads the fixtures shipped with the repository.
+func TestFileProviderFixtures(t *testing.T) {
+	for _, name := range []string{"devices.json", "all.json"} {
+		t.Run(name, func(t *testing.T) {
+			devices, err := NewFileProvider(filepath.Join("..", name)).FetchDevices(context.Background())
+			if err != nil {
+				t.Fatalf("FetchDevices: %v", err)
+			}
+			if len(devices) == 0 {
+				t.Fatal("fixture has no devices")
+			}
+		})
+	}
+}
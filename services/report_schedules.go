@@ -0,0 +1,503 @@
+// services/report_schedules.go
+package services
+
+import (
+	"bytes"
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"log"
+	"net/mail"
+	"time"
+
+	"github.com/robfig/cron/v3"
+	"gorm.io/gorm"
+
+	"github.com/alexbeattie/golangone/drivestop"
+	"github.com/alexbeattie/golangone/email"
+	"github.com/alexbeattie/golangone/export"
+	"github.com/alexbeattie/golangone/models"
+	"github.com/alexbeattie/golangone/reports"
+)
+
+var (
+	// ErrInvalidReportSchedule wraps every report schedule validation failure.
+	ErrInvalidReportSchedule = errors.New("invalid report schedule")
+	// ErrReportRunning is returned when a schedule is already being run,
+	// possibly by another replica.
+	ErrReportRunning = errors.New("report is already running")
+)
+
+const (
+	reportPollInterval = 30 * time.Second
+	reportRunTimeout   = 10 * time.Minute
+	// reportStaleAfter is when a run still marked running is taken to have
+	// been interrupted. It allows for recording the outcome of a run that
+	// used its whole timeout.
+	reportStaleAfter = reportRunTimeout + time.Minute
+	// minReportInterval is the shortest time allowed between two runs.
+	minReportInterval = time.Hour
+	// reportLockSpace is the first key of the two-key advisory locks held
+	// while a schedule runs; the second is the schedule ID.
+	reportLockSpace int32 = 0x52505254
+)
+
+// schedulePresets maps the named presets to cron expressions.
+var schedulePresets = map[string]string{
+	models.ScheduleDaily:   "0 0 * * *",
+	models.ScheduleWeekly:  "0 0 * * 1",
+	models.ScheduleMonthly: "0 0 1 * *",
+}
+
+func invalidSchedule(format string, args ...interface{}) error {
+	return fmt.Errorf("%w: %s", ErrInvalidReportSchedule, fmt.Sprintf(format, args...))
+}
+
+// parseSchedule parses a preset or standard cron expression, evaluated in timezone.
+func parseSchedule(expr, timezone string) (cron.Schedule, error) {
+	loc, err := time.LoadLocation(timezone)
+	if err != nil {
+		return nil, invalidSchedule("unknown timezone %q", timezone)
+	}
+	if preset, ok := schedulePresets[expr]; ok {
+		expr = preset
+	}
+	sched, err := cron.ParseStandard(expr)
+	if err != nil {
+		return nil, invalidSchedule("schedule: %v", err)
+	}
+	if spec, ok := sched.(*cron.SpecSchedule); ok {
+		spec.Location = loc
+	}
+	return sched, nil
+}
+
+// prevFire returns the latest time before t at which sched fires, searching
+// back up to a year. It falls back to one interval before t.
+func prevFire(sched cron.Schedule, t time.Time) time.Time {
+	gap := sched.Next(t).Sub(t)
+	for back := gap; back <= 366*24*time.Hour; back *= 2 {
+		var last time.Time
+		for next := sched.Next(t.Add(-back)); next.Before(t); next = sched.Next(next) {
+			last = next
+		}
+		if !last.IsZero() {
+			return last
+		}
+	}
+	return t.Add(-gap)
+}
+
+// checkInterval rejects a schedule that fires twice within minReportInterval
+// in the week after from.
+func checkInterval(sched cron.Schedule, from time.Time) error {
+	prev := sched.Next(from)
+	if prev.IsZero() {
+		return nil
+	}
+	end := prev.AddDate(0, 0, 7)
+	for next := sched.Next(prev); !next.IsZero() && next.Before(end); next = sched.Next(next) {
+		if next.Sub(prev) < minReportInterval {
+			return invalidSchedule("schedule must not run more often than every %s", minReportInterval)
+		}
+		prev = next
+	}
+	return nil
+}
+
+// validateReportSchedule fills in defaults and checks every field.
+func validateReportSchedule(r *models.ReportSchedule) (cron.Schedule, error) {
+	switch r.Report {
+	case models.ReportTripsCSV, models.ReportTripsXLSX, models.ReportFleetSummaryPDF:
+	default:
+		return nil, invalidSchedule("unknown report %q", r.Report)
+	}
+	if r.Name == "" {
+		r.Name = r.Report
+	}
+
+	if r.Schedule == "" {
+		return nil, invalidSchedule("schedule is required")
+	}
+	if r.Timezone == "" {
+		r.Timezone = "UTC"
+	}
+	sched, err := parseSchedule(r.Schedule, r.Timezone)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkInterval(sched, time.Now()); err != nil {
+		return nil, err
+	}
+
+	if r.Units == "" {
+		r.Units = "mi"
+	}
+	if r.Units != "mi" && r.Units != "km" {
+		return nil, invalidSchedule("units must be mi or km")
+	}
+	if r.StopDuration == "" {
+		r.StopDuration = "5m"
+	}
+	if d, err := time.ParseDuration(r.StopDuration); err != nil || d < 0 {
+		return nil, invalidSchedule("invalid stop_duration")
+	}
+
+	if len(r.Recipients) == 0 {
+		return nil, invalidSchedule("recipients must list at least one address")
+	}
+	for i, to := range r.Recipients {
+		addr, err := mail.ParseAddress(to)
+		if err != nil {
+			return nil, invalidSchedule("invalid recipient %q", to)
+		}
+		r.Recipients[i] = addr.Address
+	}
+	return sched, nil
+}
+
+func (s *Service) ListReportSchedules(userID string) ([]models.ReportSchedule, error) {
+	schedules := []models.ReportSchedule{}
+	if err := s.db.Where("user_id = ?", userID).Order("id").Find(&schedules).Error; err != nil {
+		return nil, err
+	}
+	return schedules, nil
+}
+
+func (s *Service) GetReportSchedule(userID string, id uint) (*models.ReportSchedule, error) {
+	var r models.ReportSchedule
+	if err := s.db.Where("user_id = ?", userID).First(&r, id).Error; err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
+
+// CreateReportSchedule stores a schedule and sets its first run time.
+func (s *Service) CreateReportSchedule(r *models.ReportSchedule) error {
+	if s.mailer == nil {
+		return ErrEmailNotConfigured
+	}
+	sched, err := validateReportSchedule(r)
+	if err != nil {
+		return err
+	}
+	next := sched.Next(time.Now()).UTC()
+	r.NextRunAt = &next
+	return s.db.Create(r).Error
+}
+
+// UpdateReportSchedule replaces a schedule. The next run time is recomputed
+// from now, so runs missed while a schedule was disabled are not made up.
+func (s *Service) UpdateReportSchedule(r *models.ReportSchedule) error {
+	if s.mailer == nil {
+		return ErrEmailNotConfigured
+	}
+	existing, err := s.GetReportSchedule(r.UserID, r.ID)
+	if err != nil {
+		return err
+	}
+	sched, err := validateReportSchedule(r)
+	if err != nil {
+		return err
+	}
+	next := sched.Next(time.Now()).UTC()
+	r.NextRunAt = &next
+	r.CreatedAt = existing.CreatedAt
+	r.LastRunAt, r.LastStatus, r.LastError = existing.LastRunAt, existing.LastStatus, existing.LastError
+	return s.db.Save(r).Error
+}
+
+func (s *Service) DeleteReportSchedule(userID string, id uint) error {
+	if _, err := s.GetReportSchedule(userID, id); err != nil {
+		return err
+	}
+	return s.db.Delete(&models.ReportSchedule{}, id).Error
+}
+
+// ListReportRuns returns the runs of a user's schedules, newest first. Zero
+// filters match everything; status "failed" lists failures.
+func (s *Service) ListReportRuns(userID string, scheduleID uint, status string, limit int) ([]models.ReportRun, error) {
+	if limit <= 0 || limit > MaxEventLimit {
+		limit = DefaultEventLimit
+	}
+
+	// Unscoped keeps the history of deleted schedules visible to their owner.
+	owned := s.db.Unscoped().Model(&models.ReportSchedule{}).Select("id").Where("user_id = ?", userID)
+	query := s.db.Where("schedule_id IN (?)", owned)
+	if scheduleID != 0 {
+		query = query.Where("schedule_id = ?", scheduleID)
+	}
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	runs := []models.ReportRun{}
+	if err := query.Order("id DESC").Limit(limit).Find(&runs).Error; err != nil {
+		return nil, err
+	}
+	return runs, nil
+}
+
+// RunReportScheduler runs due schedules until ctx is cancelled. Every replica
+// may run the scheduler: each schedule is run under a Postgres advisory lock
+// and re-checked once the lock is held, so only one replica runs each job.
+func (s *Service) RunReportScheduler(ctx context.Context) {
+	if err := s.failStaleReportRuns(ctx); err != nil {
+		log.Printf("Report scheduler: %v", err)
+	}
+
+	ticker := time.NewTicker(reportPollInterval)
+	defer ticker.Stop()
+
+	for {
+		var due []uint
+		err := s.db.WithContext(ctx).Model(&models.ReportSchedule{}).
+			Where("enabled = ? AND next_run_at <= ?", true, time.Now().UTC()).
+			Order("next_run_at").
+			Pluck("id", &due).Error
+		if err != nil {
+			log.Printf("Report scheduler: %v", err)
+		}
+		for _, id := range due {
+			if err := s.runDueReport(ctx, id); err != nil && !errors.Is(err, ErrReportRunning) {
+				log.Printf("Report scheduler: schedule %d: %v", id, err)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// failStaleReportRuns marks runs left "running" for longer than a run may take
+// as failed. They were interrupted by a crash or restart and would otherwise
+// show as running forever.
+func (s *Service) failStaleReportRuns(ctx context.Context) error {
+	now := time.Now().UTC()
+	result := s.db.WithContext(ctx).Model(&models.ReportRun{}).
+		Where("status = ? AND started_at < ?", models.ReportRunRunning, now.Add(-reportStaleAfter)).
+		Updates(map[string]interface{}{
+			"status":      models.ReportRunFailed,
+			"error":       "interrupted before finishing",
+			"finished_at": now,
+		})
+	if result.Error != nil {
+		return fmt.Errorf("failed to mark stale report runs: %w", result.Error)
+	}
+	if result.RowsAffected > 0 {
+		log.Printf("Report scheduler: marked %d interrupted runs as failed", result.RowsAffected)
+	}
+	return nil
+}
+
+// runDueReport runs a schedule for the period ending at its due fire time and
+// advances it to the next fire time after now.
+func (s *Service) runDueReport(ctx context.Context, id uint) error {
+	return s.withReportLock(ctx, id, func() error {
+		var r models.ReportSchedule
+		err := s.db.WithContext(ctx).
+			Where("enabled = ? AND next_run_at <= ?", true, time.Now().UTC()).
+			First(&r, id).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			// Another replica ran it before we got the lock.
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		sched, err := parseSchedule(r.Schedule, r.Timezone)
+		if err != nil {
+			return err
+		}
+		fire := *r.NextRunAt
+		run := s.executeReport(ctx, &r, prevFire(sched, fire), fire, false)
+
+		next := sched.Next(time.Now()).UTC()
+		return s.db.WithContext(ctx).Model(&r).Updates(map[string]interface{}{
+			"next_run_at": next,
+			"last_run_at": run.StartedAt,
+			"last_status": run.Status,
+			"last_error":  run.Error,
+		}).Error
+	})
+}
+
+// RunReportNow runs a schedule immediately for its most recently completed
+// period, for example to resend a report after a failed run. The schedule's
+// next run time is left unchanged.
+func (s *Service) RunReportNow(ctx context.Context, userID string, id uint) (*models.ReportRun, error) {
+	if s.mailer == nil {
+		return nil, ErrEmailNotConfigured
+	}
+	r, err := s.GetReportSchedule(userID, id)
+	if err != nil {
+		return nil, err
+	}
+	sched, err := parseSchedule(r.Schedule, r.Timezone)
+	if err != nil {
+		return nil, err
+	}
+
+	var run *models.ReportRun
+	err = s.withReportLock(ctx, id, func() error {
+		to := prevFire(sched, time.Now())
+		run = s.executeReport(ctx, r, prevFire(sched, to), to, true)
+		return s.db.WithContext(ctx).Model(r).Updates(map[string]interface{}{
+			"last_run_at": run.StartedAt,
+			"last_status": run.Status,
+			"last_error":  run.Error,
+		}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return run, nil
+}
+
+// withReportLock calls fn while holding the schedule's session advisory lock
+// on a dedicated connection. It returns ErrReportRunning if the lock is held
+// elsewhere.
+func (s *Service) withReportLock(ctx context.Context, id uint, fn func() error) error {
+	sqlDB, err := s.db.DB()
+	if err != nil {
+		return err
+	}
+	conn, err := sqlDB.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	var locked bool
+	if err := conn.QueryRowContext(ctx, "SELECT pg_try_advisory_lock($1, $2)", reportLockSpace, int32(id)).Scan(&locked); err != nil {
+		return fmt.Errorf("failed to take report lock: %w", err)
+	}
+	if !locked {
+		return ErrReportRunning
+	}
+	defer func() {
+		if _, err := conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock($1, $2)", reportLockSpace, int32(id)); err != nil {
+			// Drop the connection so its session, and the lock, end with it.
+			log.Printf("Failed to release report lock %d: %v", id, err)
+			conn.Raw(func(interface{}) error { return driver.ErrBadConn })
+		}
+	}()
+
+	return fn()
+}
+
+// executeReport generates and emails a report for [from, to], recording the
+// attempt as a run. Failures are recorded on the run rather than returned.
+func (s *Service) executeReport(ctx context.Context, r *models.ReportSchedule, from, to time.Time, manual bool) *models.ReportRun {
+	// The deadline starts before StartedAt, so a run never outlives the
+	// StartedAt + reportRunTimeout that failStaleReportRuns relies on.
+	ctx, cancel := context.WithTimeout(ctx, reportRunTimeout)
+	defer cancel()
+
+	run := &models.ReportRun{
+		ScheduleID: r.ID,
+		Status:     models.ReportRunRunning,
+		Manual:     manual,
+		PeriodFrom: from.UTC(),
+		PeriodTo:   to.UTC(),
+		StartedAt:  time.Now().UTC(),
+	}
+	if err := s.db.WithContext(ctx).Create(run).Error; err != nil {
+		log.Printf("Failed to record run of report schedule %d: %v", r.ID, err)
+	}
+
+	err := s.sendScheduledReport(ctx, r, from, to, run)
+	finished := time.Now().UTC()
+	run.FinishedAt = &finished
+	run.Status = models.ReportRunSucceeded
+	if err != nil {
+		run.Status, run.Error = models.ReportRunFailed, err.Error()
+	}
+	if err := s.db.WithContext(context.Background()).Save(run).Error; err != nil {
+		log.Printf("Failed to record run of report schedule %d: %v", r.ID, err)
+	}
+	return run
+}
+
+func (s *Service) sendScheduledReport(ctx context.Context, r *models.ReportSchedule, from, to time.Time, run *models.ReportRun) error {
+	attachment, err := s.renderScheduledReport(ctx, r, from, to)
+	if err != nil {
+		return err
+	}
+	run.Bytes = len(attachment.Data)
+
+	subject, body, err := email.RenderReport(email.ReportView{Name: r.Name, From: from, To: to})
+	if err != nil {
+		return err
+	}
+	return s.SendEmail(ctx, email.Message{
+		To:          r.Recipients,
+		Subject:     subject,
+		HTML:        body,
+		Attachments: []email.Attachment{attachment},
+	})
+}
+
+// renderScheduledReport produces a schedule's report file for [from, to].
+func (s *Service) renderScheduledReport(ctx context.Context, r *models.ReportSchedule, from, to time.Time) (email.Attachment, error) {
+	loc, err := time.LoadLocation(r.Timezone)
+	if err != nil {
+		return email.Attachment{}, err
+	}
+	minStop, err := time.ParseDuration(r.StopDuration)
+	if err != nil {
+		return email.Attachment{}, err
+	}
+	opts := drivestop.Options{MinStopDuration: minStop, Imperial: r.Units == "mi"}
+	localFrom, localTo := from.In(loc), to.In(loc)
+
+	var buf bytes.Buffer
+	switch r.Report {
+	case models.ReportTripsCSV, models.ReportTripsXLSX:
+		trips, err := s.TripReport(ctx, r.DeviceID, from, to, opts)
+		if err != nil {
+			return email.Attachment{}, err
+		}
+		rows := reports.TripRows(trips, opts.Imperial)
+		table := export.TableOptions{Imperial: opts.Imperial, Location: loc}
+
+		if r.Report == models.ReportTripsCSV {
+			if err := export.WriteTripsCSV(&buf, rows, table); err != nil {
+				return email.Attachment{}, err
+			}
+			return email.Attachment{
+				Filename:    export.TripsFilename(r.DeviceID, localFrom, localTo, "csv"),
+				ContentType: export.CSVContentType,
+				Data:        buf.Bytes(),
+			}, nil
+		}
+		if err := export.WriteTripsXLSX(&buf, rows, table); err != nil {
+			return email.Attachment{}, err
+		}
+		return email.Attachment{
+			Filename:    export.TripsFilename(r.DeviceID, localFrom, localTo, "xlsx"),
+			ContentType: export.XLSXContentType,
+			Data:        buf.Bytes(),
+		}, nil
+
+	case models.ReportFleetSummaryPDF:
+		summary, err := s.FleetSummary(ctx, r.DeviceID, from, to, opts)
+		if err != nil {
+			return email.Attachment{}, err
+		}
+		if err := export.WriteFleetSummaryPDF(&buf, summary, loc); err != nil {
+			return email.Attachment{}, err
+		}
+		return email.Attachment{
+			Filename:    export.SummaryFilename(r.DeviceID, localFrom, localTo),
+			ContentType: export.PDFContentType,
+			Data:        buf.Bytes(),
+		}, nil
+	}
+	return email.Attachment{}, fmt.Errorf("unknown report %q", r.Report)
+}
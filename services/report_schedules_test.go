@@ -0,0 +1,160 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alexbeattie/golangone/models"
+)
+
+func TestParseSchedule(t *testing.T) {
+	tests := []struct {
+		name     string
+		expr     string
+		timezone string
+		wantErr  bool
+	}{
+		{name: "daily preset", expr: models.ScheduleDaily, timezone: "America/New_York"},
+		{name: "weekly preset", expr: models.ScheduleWeekly, timezone: "America/New_York"},
+		{name: "monthly preset", expr: models.ScheduleMonthly, timezone: "America/New_York"},
+		{name: "cron expression", expr: "30 6 * * 1-5", timezone: "UTC"},
+		{name: "unknown timezone", expr: models.ScheduleDaily, timezone: "Mars/Olympus", wantErr: true},
+		{name: "bad expression", expr: "every day", timezone: "UTC", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseSchedule(tt.expr, tt.timezone)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidReportSchedule) {
+					t.Fatalf("err = %v, want ErrInvalidReportSchedule", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+// TestPrevFire covers the presets around the 2026 US DST changes (March 8 and
+// November 1), where a day is 23 or 25 hours long.
+func TestPrevFire(t *testing.T) {
+	ny, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("no tz data: %v", err)
+	}
+	at := func(month time.Month, day, hour int) time.Time {
+		return time.Date(2026, month, day, hour, 0, 0, 0, ny)
+	}
+
+	tests := []struct {
+		name     string
+		expr     string
+		t        time.Time
+		wantPrev time.Time
+		wantNext time.Time
+	}{
+		{
+			name:     "daily on spring-forward day",
+			expr:     models.ScheduleDaily,
+			t:        at(time.March, 8, 12),
+			wantPrev: at(time.March, 8, 0),
+			wantNext: at(time.March, 9, 0),
+		},
+		{
+			name:     "daily at a fire time returns the one before",
+			expr:     models.ScheduleDaily,
+			t:        at(time.March, 9, 0),
+			wantPrev: at(time.March, 8, 0),
+			wantNext: at(time.March, 10, 0),
+		},
+		{
+			name:     "daily across fall-back",
+			expr:     models.ScheduleDaily,
+			t:        at(time.November, 2, 0),
+			wantPrev: at(time.November, 1, 0),
+			wantNext: at(time.November, 3, 0),
+		},
+		{
+			name:     "weekly across spring-forward",
+			expr:     models.ScheduleWeekly,
+			t:        at(time.March, 9, 0),
+			wantPrev: at(time.March, 2, 0),
+			wantNext: at(time.March, 16, 0),
+		},
+		{
+			name:     "weekly mid-week",
+			expr:     models.ScheduleWeekly,
+			t:        at(time.November, 4, 9),
+			wantPrev: at(time.November, 2, 0),
+			wantNext: at(time.November, 9, 0),
+		},
+		{
+			name:     "monthly on fall-back day",
+			expr:     models.ScheduleMonthly,
+			t:        at(time.November, 1, 12),
+			wantPrev: at(time.November, 1, 0),
+			wantNext: at(time.December, 1, 0),
+		},
+		{
+			name:     "monthly at a fire time spanning spring-forward",
+			expr:     models.ScheduleMonthly,
+			t:        at(time.April, 1, 0),
+			wantPrev: at(time.March, 1, 0),
+			wantNext: at(time.May, 1, 0),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sched, err := parseSchedule(tt.expr, "America/New_York")
+			if err != nil {
+				t.Fatalf("parseSchedule: %v", err)
+			}
+			if got := prevFire(sched, tt.t); !got.Equal(tt.wantPrev) {
+				t.Errorf("prevFire = %s, want %s", got.In(ny), tt.wantPrev)
+			}
+			if got := sched.Next(tt.t); !got.Equal(tt.wantNext) {
+				t.Errorf("Next = %s, want %s", got.In(ny), tt.wantNext)
+			}
+		})
+	}
+}
+
+func TestCheckInterval(t *testing.T) {
+	from := time.Date(2026, 3, 2, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		expr    string
+		wantErr bool
+	}{
+		{name: "daily preset", expr: models.ScheduleDaily},
+		{name: "hourly", expr: "0 * * * *"},
+		{name: "every minute", expr: "* * * * *", wantErr: true},
+		{name: "every 30 minutes", expr: "*/30 * * * *", wantErr: true},
+		{name: "two runs close together once a week", expr: "0,10 9 * * 1", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sched, err := parseSchedule(tt.expr, "UTC")
+			if err != nil {
+				t.Fatalf("parseSchedule: %v", err)
+			}
+			err = checkInterval(sched, from)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidReportSchedule) {
+					t.Fatalf("err = %v, want ErrInvalidReportSchedule", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,106 @@
+// services/reports.go
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/alexbeattie/golangone/drivestop"
+	"github.com/alexbeattie/golangone/models"
+	"github.com/alexbeattie/golangone/reports"
+)
+
+// reportDeviceIDs returns deviceID alone, or every device with stored points
+// in the window when deviceID is empty.
+func (s *Service) reportDeviceIDs(ctx context.Context, deviceID string, from, to time.Time) ([]string, error) {
+	if deviceID != "" {
+		return []string{deviceID}, nil
+	}
+
+	var ids []string
+	err := s.db.WithContext(ctx).Model(&models.StoredDevicePoint{}).
+		Where("dt_tracker BETWEEN ? AND ?", from, to).
+		Distinct("device_id").
+		Order("device_id").
+		Pluck("device_id", &ids).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list devices: %w", err)
+	}
+	return ids, nil
+}
+
+// SpeedingReport detects speeding incidents for one device, or the whole fleet
+// when deviceID is empty.
+func (s *Service) SpeedingReport(deviceID string, from, to time.Time, opts reports.SpeedingOptions) ([]reports.SpeedingIncident, error) {
+	ids, err := s.reportDeviceIDs(context.Background(), deviceID, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	incidents := []reports.SpeedingIncident{}
+	for _, id := range ids {
+		points, err := s.StoredPoints(id, from, to)
+		if err != nil {
+			return nil, err
+		}
+		incidents = append(incidents, reports.SpeedingIncidents(points, opts)...)
+	}
+	return incidents, nil
+}
+
+// TripReport segments the window into drives, idles and stops for one device,
+// or the whole fleet when deviceID is empty.
+func (s *Service) TripReport(ctx context.Context, deviceID string, from, to time.Time, opts drivestop.Options) ([]reports.DeviceTrips, error) {
+	ids, err := s.reportDeviceIDs(ctx, deviceID, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	trips := make([]reports.DeviceTrips, 0, len(ids))
+	for _, id := range ids {
+		route, err := s.ComputeDriveStops(ctx, id, from, to, opts)
+		if err != nil {
+			return nil, err
+		}
+		trips = append(trips, reports.DeviceTrips{DeviceID: id, Name: s.DeviceName(id), Route: route})
+	}
+	return trips, nil
+}
+
+// thumbnailSpacing is the initial spacing in meters between points kept for
+// route thumbnails.
+const thumbnailSpacing = 25
+
+// FleetSummary summarizes each device's activity over the window, for one
+// device or the whole fleet when deviceID is empty. Points are read once per
+// device, feeding both the drive-stop segmenter and the thumbnail outline.
+func (s *Service) FleetSummary(ctx context.Context, deviceID string, from, to time.Time, opts drivestop.Options) (*reports.FleetSummary, error) {
+	ids, err := s.reportDeviceIDs(ctx, deviceID, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	summary := &reports.FleetSummary{From: from, To: to, Imperial: opts.Imperial, Devices: []reports.DeviceSummary{}}
+	for _, id := range ids {
+		settings, err := s.DeviceSettings(id)
+		if err != nil {
+			return nil, err
+		}
+
+		seg := drivestop.NewSegmenter(settings)
+		path := reports.Path{MinSpacing: thumbnailSpacing}
+		err = s.EachStoredPoint(ctx, id, from, to, func(p models.StoredDevicePoint) error {
+			seg.Add(p)
+			path.Add(p.Lat, p.Lng)
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		trips := reports.DeviceTrips{DeviceID: id, Name: s.DeviceName(id), Route: seg.Result(from, to, opts)}
+		summary.Devices = append(summary.Devices, reports.Summarize(trips, path.Points))
+	}
+	return summary, nil
+}
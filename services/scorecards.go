@@ -0,0 +1,160 @@
+// services/scorecards.go
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"gorm.io/gorm/clause"
+
+	"github.com/alexbeattie/golangone/drivestop"
+	"github.com/alexbeattie/golangone/models"
+	"github.com/alexbeattie/golangone/reports"
+)
+
+// WeekStart returns Monday 00:00 UTC of the week containing t.
+func WeekStart(t time.Time) time.Time {
+	t = t.UTC()
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	offset := (int(day.Weekday()) + 6) % 7
+	return day.AddDate(0, 0, -offset)
+}
+
+// Scorecards returns the fleet's safety scores for the week starting at
+// weekStart, ranked best first. Completed weeks are computed once and stored;
+// the current week is computed live.
+func (s *Service) Scorecards(weekStart time.Time) ([]models.SafetyScore, error) {
+	weekStart = WeekStart(weekStart)
+	complete := !weekStart.AddDate(0, 0, 7).After(time.Now())
+
+	if complete {
+		var stored []models.SafetyScore
+		if err := s.db.Where("week_start = ?", weekStart).Find(&stored).Error; err != nil {
+			return nil, fmt.Errorf("failed to load scorecards: %w", err)
+		}
+		if len(stored) > 0 {
+			return rankScores(stored), nil
+		}
+	}
+
+	scores, err := s.computeScores(weekStart)
+	if err != nil {
+		return nil, err
+	}
+
+	if complete && len(scores) > 0 {
+		err := s.db.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "week_start"}, {Name: "device_id"}},
+			UpdateAll: true,
+		}).Create(&scores).Error
+		if err != nil {
+			return nil, fmt.Errorf("failed to store scorecards: %w", err)
+		}
+	}
+	return rankScores(scores), nil
+}
+
+func (s *Service) computeScores(weekStart time.Time) ([]models.SafetyScore, error) {
+	from, to := weekStart, weekStart.AddDate(0, 0, 7)
+	ids, err := s.reportDeviceIDs(context.Background(), "", from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	scores := make([]models.SafetyScore, 0, len(ids))
+	for _, id := range ids {
+		score, err := s.computeScore(id, from, to)
+		if err != nil {
+			return nil, err
+		}
+		scores = append(scores, score)
+	}
+	return scores, nil
+}
+
+func (s *Service) computeScore(deviceID string, from, to time.Time) (models.SafetyScore, error) {
+	score := models.SafetyScore{DeviceID: deviceID, WeekStart: from, ComputedAt: time.Now().UTC()}
+
+	var record models.DeviceRecord
+	if s.db.Select("display_name").First(&record, "device_id = ?", deviceID).Error == nil {
+		score.DisplayName = record.DisplayName
+	}
+
+	settings, err := s.DeviceSettings(deviceID)
+	if err != nil {
+		return score, err
+	}
+	points, err := s.StoredPoints(deviceID, from, to)
+	if err != nil {
+		return score, err
+	}
+	score.DistanceMiles = drivestop.Compute(points, from, to, settings, drivestop.Options{Imperial: true}).Distance.Value
+	score.SpeedingIncidents = len(reports.SpeedingIncidents(points, reports.DefaultSpeedingOptions))
+
+	var counts []struct {
+		Subtype string
+		Count   int
+	}
+	err = s.db.Model(&models.Event{}).
+		Select("subtype, count(*) AS count").
+		Where("type = ? AND device_id = ? AND occurred_at >= ? AND occurred_at < ?", models.EventTypeHarsh, deviceID, from, to).
+		Group("subtype").
+		Scan(&counts).Error
+	if err != nil {
+		return score, fmt.Errorf("failed to count harsh events: %w", err)
+	}
+	for _, c := range counts {
+		switch c.Subtype {
+		case models.HarshBraking:
+			score.HarshBraking = c.Count
+		case models.HarshAcceleration:
+			score.HarshAcceleration = c.Count
+		case models.HarshCornering:
+			score.HarshCornering = c.Count
+		}
+	}
+
+	score.Score = reports.SafetyScore(score.DistanceMiles,
+		score.HarshBraking, score.HarshAcceleration, score.HarshCornering, score.SpeedingIncidents)
+	return score, nil
+}
+
+// rankScores sorts best first and numbers the ranks; equal scores share a rank.
+func rankScores(scores []models.SafetyScore) []models.SafetyScore {
+	sort.SliceStable(scores, func(i, j int) bool {
+		if scores[i].Score != scores[j].Score {
+			return scores[i].Score > scores[j].Score
+		}
+		return scores[i].DistanceMiles > scores[j].DistanceMiles
+	})
+	for i := range scores {
+		scores[i].Rank = i + 1
+		if i > 0 && scores[i].Score == scores[i-1].Score {
+			scores[i].Rank = scores[i-1].Rank
+		}
+	}
+	return scores
+}
+
+// RunWeeklyScorecards makes sure the previous week's scorecards are stored,
+// checking hourly until ctx is cancelled.
+func (s *Service) RunWeeklyScorecards(ctx context.Context) {
+	ticker := time.NewTicker(time.Hour)
+	defer ticker.Stop()
+
+	for {
+		lastWeek := WeekStart(time.Now()).AddDate(0, 0, -7)
+		if _, err := s.Scorecards(lastWeek); err != nil {
+			log.Printf("Weekly scorecards failed: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -2,28 +2,92 @@
 package services
 
 import (
-	"encoding/json"
-	"fmt"
+	"context"
 	"net/http"
 	"time"
 
+	"golang.org/x/sync/singleflight"
+	"golang.org/x/time/rate"
 	"gorm.io/gorm"
 	"github.com/alexbeattie/golangone/config"
+	"github.com/alexbeattie/golangone/email"
+	"github.com/alexbeattie/golangone/geocode"
 	"github.com/alexbeattie/golangone/models"
+	"github.com/alexbeattie/golangone/stream"
 )
 
 type Service struct {
-	db     *gorm.DB
-	config *config.Config
-	client *http.Client
+	db       *gorm.DB
+	config   *config.Config
+	provider TrackingProvider
+	devices  *deviceCache
+	hub      *stream.Hub
+
+	webhookClient *http.Client
+	mailer        email.Mailer
+
+	geocoder       geocode.Provider
+	geocodeLimiter *rate.Limiter
+	geocodeGroup   singleflight.Group
 }
 
+// NewService builds a Service backed by the upstream selected in config: a
+// local fixture file when OneStepGPSFixture is set, the OneStepGPS API otherwise.
 func NewService(db *gorm.DB, config *config.Config) *Service {
-	return &Service{
-		db:     db,
-		config: config,
-		client: &http.Client{Timeout: 10 * time.Second},
+	var provider TrackingProvider
+	if config.OneStepGPSFixture != "" {
+		provider = NewFileProvider(config.OneStepGPSFixture)
+	} else {
+		provider = NewOneStepGPSClient(config)
+	}
+	return NewServiceWithProvider(db, config, provider)
+}
+
+// NewServiceWithProvider builds a Service around an explicit TrackingProvider.
+func NewServiceWithProvider(db *gorm.DB, config *config.Config, provider TrackingProvider) *Service {
+	s := &Service{
+		db:       db,
+		config:   config,
+		provider: provider,
+		hub:      stream.NewHub(config.StreamBacklog),
+
+		webhookClient: &http.Client{Timeout: 10 * time.Second},
+	}
+	if config.SMTPHost != "" {
+		s.mailer = &email.SMTPMailer{
+			Host:     config.SMTPHost,
+			Port:     config.SMTPPort,
+			Username: config.SMTPUsername,
+			Password: config.SMTPPassword,
+			From:     config.SMTPFrom,
+		}
+	}
+	s.geocoder = newGeocoder(config)
+	s.geocodeLimiter = newGeocodeLimiter(config.GeocodeRateLimit)
+	s.devices = newDeviceCache(func() ([]models.Device, error) {
+		// The cache is shared, so a refresh must not die with one request.
+		return provider.FetchDevices(context.Background())
+	}, config.DeviceCacheTTL, config.DeviceCacheMaxStale)
+	return s
+}
+
+func (s *Service) Config() *config.Config {
+	return s.config
+}
+
+// DeviceHub returns the hub that broadcasts device changes seen by the ingestor.
+func (s *Service) DeviceHub() *stream.Hub {
+	return s.hub
+}
+
+// UpstreamBreaker returns the provider's circuit breaker state, or false if the
+// provider is not guarded by one.
+func (s *Service) UpstreamBreaker() (BreakerSnapshot, bool) {
+	reporter, ok := s.provider.(breakerReporter)
+	if !ok {
+		return BreakerSnapshot{}, false
 	}
+	return reporter.BreakerState(), true
 }
 
 func (s *Service) GetPreferences(userID uint) (*models.UserPreferences, error) {
@@ -38,22 +102,27 @@ func (s *Service) UpdatePreferences(preferences *models.UserPreferences) error {
 	return s.db.Save(preferences).Error
 }
 
+// FetchDevices returns the cached device list, refreshing it as needed.
 func (s *Service) FetchDevices() ([]models.Device, error) {
-	url := fmt.Sprintf("https://track.onestepgps.com/v3/api/public/device?latest_point=true&api-key=%s",
-		s.config.OneStepGPSAPIKey)
-	
-	resp, err := s.client.Get(url)
+	snap, err := s.devices.get()
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch devices: %w", err)
+		return nil, err
 	}
-	defer resp.Body.Close()
+	return snap.Devices, nil
+}
 
-	var response models.APIResponse
-	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
-		return nil, fmt.Errorf("failed to decode response: %w", err)
-	}
+// DeviceSnapshot returns the cached device list along with its age.
+func (s *Service) DeviceSnapshot() (DeviceSnapshot, error) {
+	return s.devices.get()
+}
 
-	return response.ResultList, nil
+// RefreshDevices bypasses the TTL and fetches a new device list, updating the cache.
+func (s *Service) RefreshDevices() ([]models.Device, error) {
+	snap, err := s.devices.refresh()
+	if err != nil {
+		return nil, err
+	}
+	return snap.Devices, nil
 }
 // // func (s *Service) FetchDeviceOdometer(deviceID string) (*models.OdometerResponse, error) {
 //     url := fmt.Sprintf("https://track.onestepgps.com/v3/api/public/odometer/%s?api-key=%s",
@@ -72,43 +141,10 @@ func (s *Service) FetchDevices() ([]models.Device, error) {
 
 //     return &response, nil
 // }
-// services/service.go
-func (s *Service) FetchDeviceInfo(params map[string]string) (*models.DeviceInfoResponse, error) {
-    url := fmt.Sprintf("https://track.onestepgps.com/v3/api/public/device-info?lat_lng=1&api-key=%s",
-        s.config.OneStepGPSAPIKey)
-    
-    resp, err := s.client.Get(url)
-    if err != nil {
-        return nil, fmt.Errorf("failed to fetch device info: %w", err)
-    }
-    defer resp.Body.Close()
-
-    var response models.DeviceInfoResponse
-    if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
-        return nil, fmt.Errorf("failed to decode device info response: %w", err)
-    }
-
-    return &response, nil
+func (s *Service) FetchDeviceInfo(ctx context.Context, params map[string]string) (*models.DeviceInfoResponse, error) {
+	return s.provider.FetchDeviceInfo(ctx, params)
+}
+
+func (s *Service) FetchDriveStopRoute(ctx context.Context, deviceID string, fromTime, toTime time.Time, stopDuration string) (*models.DriveStopResponse, error) {
+	return s.provider.FetchDriveStopRoute(ctx, deviceID, fromTime, toTime, stopDuration)
 }
-func (s *Service) FetchDriveStopRoute(deviceID string, fromTime, toTime time.Time, stopDuration string) (*models.DriveStopResponse, error) {
-    url := fmt.Sprintf("https://track.onestepgps.com/v3/api/public/route/drive-stop?device_id=%s&dt_tracker_from=%s&dt_tracker_to=%s&stop_duration=%s&return_points=true&max_return_points=999",
-        deviceID,
-        fromTime.Format(time.RFC3339),
-        toTime.Format(time.RFC3339),
-        stopDuration)
-    
-    url = fmt.Sprintf("%s&api-key=%s", url, s.config.OneStepGPSAPIKey)
-    
-    resp, err := s.client.Get(url)
-    if err != nil {
-        return nil, fmt.Errorf("failed to fetch drive-stop route: %w", err)
-    }
-    defer resp.Body.Close()
-
-    var response models.DriveStopResponse
-    if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
-        return nil, fmt.Errorf("failed to decode drive-stop response: %w", err)
-    }
-
-    return &response, nil
-}
\ No newline at end of file
@@ -0,0 +1,114 @@
+// services/webhook_dispatcher.go
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+	"math/rand/v2"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/alexbeattie/golangone/models"
+)
+
+const (
+	webhookPollInterval = 5 * time.Second
+	webhookBatchSize    = 50
+	webhookMaxAttempts  = 8
+	webhookBaseBackoff  = 30 * time.Second
+	webhookMaxBackoff   = 6 * time.Hour
+	// webhookLease is how long a claimed delivery is hidden from other
+	// dispatchers; it must outlast the webhook client timeout.
+	webhookLease = time.Minute
+)
+
+// RunWebhookDispatcher delivers due outbox rows until ctx is cancelled. Rows
+// are claimed with FOR UPDATE SKIP LOCKED, so several replicas can run the
+// dispatcher without sending the same delivery twice.
+func (s *Service) RunWebhookDispatcher(ctx context.Context) {
+	ticker := time.NewTicker(webhookPollInterval)
+	defer ticker.Stop()
+
+	for {
+		for i := 0; i < webhookBatchSize; i++ {
+			found, err := s.dispatchOne(ctx)
+			if err != nil {
+				log.Printf("Webhook dispatcher: %v", err)
+				break
+			}
+			if !found {
+				break
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// dispatchOne claims and attempts a single due delivery, reporting whether
+// there was one. The row is claimed by pushing next_attempt_at out by
+// webhookLease and committing, so no lock or connection is held while the
+// receiver responds; a dispatcher that dies mid-send leaves the delivery to
+// be retried once the lease expires.
+func (s *Service) dispatchOne(ctx context.Context) (bool, error) {
+	var d models.WebhookDelivery
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND next_attempt_at <= ?", models.DeliveryPending, time.Now().UTC()).
+			Order("next_attempt_at").
+			First(&d).Error
+		if err != nil {
+			return err
+		}
+		return tx.Model(&d).Update("next_attempt_at", time.Now().UTC().Add(webhookLease)).Error
+	})
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	db := s.db.WithContext(ctx)
+	var w models.WebhookSubscription
+	if err := db.First(&w, d.SubscriptionID).Error; err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return true, err
+		}
+		d.Status, d.LastError = models.DeliveryDead, "subscription deleted"
+		return true, db.Save(&d).Error
+	}
+
+	result := s.sendWebhook(ctx, &w, strconv.FormatUint(uint64(d.ID), 10), d.EventType, []byte(d.Payload))
+	d.Attempts++
+	d.LastStatusCode, d.LastError = result.StatusCode, result.Error
+
+	switch {
+	case result.ok():
+		now := time.Now().UTC()
+		d.Status, d.DeliveredAt = models.DeliveryDelivered, &now
+	case d.Attempts >= webhookMaxAttempts:
+		d.Status = models.DeliveryDead
+	default:
+		d.NextAttemptAt = time.Now().UTC().Add(webhookBackoff(d.Attempts))
+	}
+	return true, db.Save(&d).Error
+}
+
+// webhookBackoff doubles from webhookBaseBackoff per attempt, capped at
+// webhookMaxBackoff, plus up to 20% jitter.
+func webhookBackoff(attempts int) time.Duration {
+	d := webhookBaseBackoff << (attempts - 1)
+	if d <= 0 || d > webhookMaxBackoff {
+		d = webhookMaxBackoff
+	}
+	return d + rand.N(d/5+1)
+}
@@ -0,0 +1,245 @@
+// services/webhooks.go
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/alexbeattie/golangone/models"
+)
+
+// ErrInvalidWebhook wraps every webhook subscription validation failure.
+var ErrInvalidWebhook = errors.New("invalid webhook")
+
+// Headers sent with every webhook request. The signature is the hex
+// HMAC-SHA256 of "<timestamp>.<body>" keyed with the subscription secret.
+const (
+	HeaderWebhookEvent     = "X-Webhook-Event"
+	HeaderWebhookDelivery  = "X-Webhook-Delivery"
+	HeaderWebhookTimestamp = "X-Webhook-Timestamp"
+	HeaderWebhookSignature = "X-Webhook-Signature"
+)
+
+// WebhookEnvelope is the JSON body posted to subscribers.
+type WebhookEnvelope struct {
+	Type      string      `json:"type"`
+	CreatedAt time.Time   `json:"created_at"`
+	Data      interface{} `json:"data"`
+}
+
+// WebhookResult describes one HTTP attempt.
+type WebhookResult struct {
+	StatusCode int    `json:"status_code,omitempty"`
+	Error      string `json:"error,omitempty"`
+	Body       string `json:"body,omitempty"`
+}
+
+func (r WebhookResult) ok() bool {
+	return r.Error == "" && r.StatusCode >= 200 && r.StatusCode < 300
+}
+
+// SignWebhook returns the signature header value for body sent at timestamp.
+func SignWebhook(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "%d.", timestamp)
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func validateWebhook(w *models.WebhookSubscription) error {
+	u, err := url.Parse(w.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("%w: url must be an absolute http(s) URL", ErrInvalidWebhook)
+	}
+	if len(w.EventTypes) == 0 {
+		return fmt.Errorf("%w: event_types must list at least one type", ErrInvalidWebhook)
+	}
+	if w.Secret == "" {
+		buf := make([]byte, 32)
+		if _, err := rand.Read(buf); err != nil {
+			return err
+		}
+		w.Secret = hex.EncodeToString(buf)
+	}
+	return nil
+}
+
+func (s *Service) ListWebhooks() ([]models.WebhookSubscription, error) {
+	hooks := []models.WebhookSubscription{}
+	if err := s.db.Order("id").Find(&hooks).Error; err != nil {
+		return nil, err
+	}
+	return hooks, nil
+}
+
+func (s *Service) GetWebhook(id uint) (*models.WebhookSubscription, error) {
+	var w models.WebhookSubscription
+	if err := s.db.First(&w, id).Error; err != nil {
+		return nil, err
+	}
+	return &w, nil
+}
+
+// CreateWebhook stores a subscription, generating a secret if none was given.
+func (s *Service) CreateWebhook(w *models.WebhookSubscription) error {
+	if err := validateWebhook(w); err != nil {
+		return err
+	}
+	return s.db.Create(w).Error
+}
+
+// UpdateWebhook replaces a subscription. An empty secret keeps the current one.
+func (s *Service) UpdateWebhook(w *models.WebhookSubscription) error {
+	existing, err := s.GetWebhook(w.ID)
+	if err != nil {
+		return err
+	}
+	if w.Secret == "" {
+		w.Secret = existing.Secret
+	}
+	if err := validateWebhook(w); err != nil {
+		return err
+	}
+	w.CreatedAt = existing.CreatedAt
+	return s.db.Save(w).Error
+}
+
+func (s *Service) DeleteWebhook(id uint) error {
+	if _, err := s.GetWebhook(id); err != nil {
+		return err
+	}
+	return s.db.Delete(&models.WebhookSubscription{}, id).Error
+}
+
+// ListWebhookDeliveries returns outbox rows, newest first. Zero filters match everything.
+func (s *Service) ListWebhookDeliveries(subscriptionID uint, status string, limit int) ([]models.WebhookDelivery, error) {
+	if limit <= 0 || limit > MaxEventLimit {
+		limit = DefaultEventLimit
+	}
+
+	query := s.db.Model(&models.WebhookDelivery{})
+	if subscriptionID != 0 {
+		query = query.Where("subscription_id = ?", subscriptionID)
+	}
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	deliveries := []models.WebhookDelivery{}
+	if err := query.Order("id DESC").Limit(limit).Find(&deliveries).Error; err != nil {
+		return nil, err
+	}
+	return deliveries, nil
+}
+
+// RetryWebhookDelivery puts a delivery back in the queue for an immediate attempt.
+func (s *Service) RetryWebhookDelivery(id uint) (*models.WebhookDelivery, error) {
+	var d models.WebhookDelivery
+	if err := s.db.First(&d, id).Error; err != nil {
+		return nil, err
+	}
+	d.Status, d.NextAttemptAt = models.DeliveryPending, time.Now().UTC()
+	if err := s.db.Save(&d).Error; err != nil {
+		return nil, err
+	}
+	return &d, nil
+}
+
+// TestWebhook sends a signed ping to a subscription right away, bypassing
+// the outbox, and reports the outcome.
+func (s *Service) TestWebhook(ctx context.Context, id uint) (WebhookResult, error) {
+	w, err := s.GetWebhook(id)
+	if err != nil {
+		return WebhookResult{}, err
+	}
+
+	body, err := json.Marshal(WebhookEnvelope{
+		Type:      models.NotifyPing,
+		CreatedAt: time.Now().UTC(),
+		Data:      map[string]interface{}{"subscription_id": w.ID},
+	})
+	if err != nil {
+		return WebhookResult{}, err
+	}
+	return s.sendWebhook(ctx, w, "test", models.NotifyPing, body), nil
+}
+
+// publish queues a notification for every enabled subscription that wants
+// its type. It writes through tx so the deliveries commit or roll back
+// together with the event or alert they announce.
+func (s *Service) publish(tx *gorm.DB, notifyType string, data interface{}) error {
+	var hooks []models.WebhookSubscription
+	if err := tx.Where("enabled = ?", true).Find(&hooks).Error; err != nil {
+		return fmt.Errorf("failed to load webhook subscriptions: %w", err)
+	}
+
+	var body []byte
+	for _, w := range hooks {
+		if !slices.Contains(w.EventTypes, notifyType) && !slices.Contains(w.EventTypes, models.NotifyAll) {
+			continue
+		}
+
+		if body == nil {
+			var err error
+			body, err = json.Marshal(WebhookEnvelope{Type: notifyType, CreatedAt: time.Now().UTC(), Data: data})
+			if err != nil {
+				return fmt.Errorf("failed to encode %s: %w", notifyType, err)
+			}
+		}
+
+		d := models.WebhookDelivery{
+			SubscriptionID: w.ID,
+			EventType:      notifyType,
+			Payload:        string(body),
+			Status:         models.DeliveryPending,
+			NextAttemptAt:  time.Now().UTC(),
+		}
+		if err := tx.Create(&d).Error; err != nil {
+			return fmt.Errorf("failed to queue %s for subscription %d: %w", notifyType, w.ID, err)
+		}
+	}
+	return nil
+}
+
+// sendWebhook performs one signed POST.
+func (s *Service) sendWebhook(ctx context.Context, w *models.WebhookSubscription, deliveryID, notifyType string, body []byte) WebhookResult {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.URL, bytes.NewReader(body))
+	if err != nil {
+		return WebhookResult{Error: err.Error()}
+	}
+
+	ts := time.Now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderWebhookEvent, notifyType)
+	req.Header.Set(HeaderWebhookDelivery, deliveryID)
+	req.Header.Set(HeaderWebhookTimestamp, strconv.FormatInt(ts, 10))
+	req.Header.Set(HeaderWebhookSignature, SignWebhook(w.Secret, ts, body))
+
+	resp, err := s.webhookClient.Do(req)
+	if err != nil {
+		return WebhookResult{Error: err.Error()}
+	}
+	defer resp.Body.Close()
+
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+	result := WebhookResult{StatusCode: resp.StatusCode, Body: string(respBody)}
+	if !result.ok() {
+		result.Error = fmt.Sprintf("unexpected status %d", resp.StatusCode)
+	}
+	return result
+}
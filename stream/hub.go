@@ -0,0 +1,268 @@
+// Package stream turns successive device lists into change events and fans
+// them out to live subscribers (SSE and WebSocket clients).
+package stream
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/alexbeattie/golangone/models"
+)
+
+// Change kinds reported in DeviceDiff.Changes.
+const (
+	ChangeAdded       = "added"
+	ChangePosition    = "position"
+	ChangeOnline      = "online"
+	ChangeOffline     = "offline"
+	ChangeDriveStatus = "drive_status"
+)
+
+var (
+	// ErrResumeGap is returned when a subscriber asks to resume from an event
+	// that is no longer (or was never) in the backlog, including events from
+	// before the process restarted.
+	ErrResumeGap = errors.New("requested event is outside the backlog")
+	// ErrInvalidEventID is returned for a resume ID that is not "<epoch>-<seq>".
+	ErrInvalidEventID = errors.New("invalid event id")
+)
+
+// DeviceDiff describes what changed for one device between two polls. The
+// latest point is only included when the position changed.
+type DeviceDiff struct {
+	DeviceID    string              `json:"device_id"`
+	DisplayName string              `json:"display_name"`
+	Changes     []string            `json:"changes"`
+	Online      bool                `json:"online"`
+	DriveStatus string              `json:"drive_status"`
+	GroupIDs    []string            `json:"group_ids,omitempty"`
+	Point       *models.DevicePoint `json:"latest_device_point,omitempty"`
+}
+
+// Event is a numbered DeviceDiff. IDs are "<epoch>-<seq>": the epoch is the
+// hub's start time in Unix seconds and seq increases by one per event, so IDs
+// from before a restart can't be mistaken for current ones.
+type Event struct {
+	ID   string     `json:"id"`
+	Time time.Time  `json:"time"`
+	Diff DeviceDiff `json:"diff"`
+	seq  uint64
+}
+
+type deviceState struct {
+	pointID     string
+	online      bool
+	driveStatus string
+}
+
+// Hub diffs device lists and broadcasts the resulting events. The most recent
+// events are kept so reconnecting clients can resume from their last ID.
+type Hub struct {
+	mu          sync.Mutex
+	backlogSize int
+	backlog     []Event
+	epoch       int64
+	lastID      uint64
+	state       map[string]deviceState
+	subs        map[*Subscription]struct{}
+}
+
+func NewHub(backlogSize int) *Hub {
+	if backlogSize < 1 {
+		backlogSize = 1
+	}
+	return &Hub{
+		backlogSize: backlogSize,
+		epoch:       time.Now().Unix(),
+		state:       make(map[string]deviceState),
+		subs:        make(map[*Subscription]struct{}),
+	}
+}
+
+// Subscription receives live events on C. C is closed when the subscription
+// is closed or when the subscriber fell too far behind and was dropped.
+type Subscription struct {
+	C       <-chan Event
+	ch      chan Event
+	hub     *Hub
+	dropped bool
+}
+
+// Dropped reports whether the hub closed the subscription because its buffer filled up.
+func (s *Subscription) Dropped() bool {
+	s.hub.mu.Lock()
+	defer s.hub.mu.Unlock()
+	return s.dropped
+}
+
+// Close unsubscribes. It is safe to call more than once.
+func (s *Subscription) Close() {
+	s.hub.mu.Lock()
+	defer s.hub.mu.Unlock()
+	s.hub.remove(s)
+}
+
+// Subscribe registers a subscriber with the given channel buffer. If
+// lastEventID is not empty, the backlog events after it are returned for
+// replay; ErrResumeGap is returned alongside a valid subscription when those
+// events are no longer available and the client must resync. A malformed
+// lastEventID fails with ErrInvalidEventID and no subscription.
+func (h *Hub) Subscribe(lastEventID string, buffer int) (*Subscription, []Event, error) {
+	var epoch int64
+	var lastSeq uint64
+	if lastEventID != "" {
+		var err error
+		if epoch, lastSeq, err = parseEventID(lastEventID); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	ch := make(chan Event, buffer)
+	sub := &Subscription{C: ch, ch: ch, hub: h}
+	h.subs[sub] = struct{}{}
+
+	if lastEventID == "" || (epoch == h.epoch && lastSeq == h.lastID) {
+		return sub, nil, nil
+	}
+	if epoch != h.epoch || lastSeq > h.lastID || len(h.backlog) == 0 || lastSeq < h.backlog[0].seq-1 {
+		return sub, nil, ErrResumeGap
+	}
+
+	start := int(lastSeq - h.backlog[0].seq + 1)
+	replay := make([]Event, len(h.backlog)-start)
+	copy(replay, h.backlog[start:])
+	return sub, replay, nil
+}
+
+func parseEventID(id string) (int64, uint64, error) {
+	epochStr, seqStr, ok := strings.Cut(id, "-")
+	if !ok {
+		return 0, 0, ErrInvalidEventID
+	}
+	epoch, err := strconv.ParseInt(epochStr, 10, 64)
+	if err != nil {
+		return 0, 0, ErrInvalidEventID
+	}
+	seq, err := strconv.ParseUint(seqStr, 10, 64)
+	if err != nil {
+		return 0, 0, ErrInvalidEventID
+	}
+	return epoch, seq, nil
+}
+
+// DevicesPolled implements services.PollObserver.
+func (h *Hub) DevicesPolled(ctx context.Context, devices []models.Device, stored []models.StoredDevicePoint) {
+	h.Publish(devices)
+}
+
+// Publish diffs devices against the previous list and broadcasts one event per changed device.
+func (h *Hub) Publish(devices []models.Device) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	now := time.Now().UTC()
+	for _, d := range devices {
+		next := deviceState{
+			pointID:     d.LatestDevicePoint.DevicePointID,
+			online:      d.Online,
+			driveStatus: d.LatestDevicePoint.DeviceState.DriveStatus,
+		}
+		prev, seen := h.state[d.DeviceID]
+		h.state[d.DeviceID] = next
+
+		changes := changesBetween(prev, next, seen)
+		if len(changes) == 0 {
+			continue
+		}
+
+		diff := DeviceDiff{
+			DeviceID:    d.DeviceID,
+			DisplayName: d.DisplayName,
+			Changes:     changes,
+			Online:      d.Online,
+			DriveStatus: next.driveStatus,
+			GroupIDs:    GroupIDs(d),
+		}
+		if !seen || prev.pointID != next.pointID {
+			point := d.LatestDevicePoint
+			diff.Point = &point
+		}
+
+		h.lastID++
+		h.broadcast(Event{ID: fmt.Sprintf("%d-%d", h.epoch, h.lastID), Time: now, Diff: diff, seq: h.lastID})
+	}
+}
+
+func changesBetween(prev, next deviceState, seen bool) []string {
+	if !seen {
+		return []string{ChangeAdded}
+	}
+
+	var changes []string
+	if prev.pointID != next.pointID {
+		changes = append(changes, ChangePosition)
+	}
+	if prev.online != next.online {
+		if next.online {
+			changes = append(changes, ChangeOnline)
+		} else {
+			changes = append(changes, ChangeOffline)
+		}
+	}
+	if prev.driveStatus != next.driveStatus {
+		changes = append(changes, ChangeDriveStatus)
+	}
+	return changes
+}
+
+// broadcast records ev in the backlog and delivers it to every subscriber.
+// Subscribers whose buffer is full are dropped rather than blocking the hub.
+// Callers must hold h.mu.
+func (h *Hub) broadcast(ev Event) {
+	h.backlog = append(h.backlog, ev)
+	if len(h.backlog) > h.backlogSize {
+		h.backlog = h.backlog[len(h.backlog)-h.backlogSize:]
+	}
+
+	for sub := range h.subs {
+		select {
+		case sub.ch <- ev:
+		default:
+			sub.dropped = true
+			h.remove(sub)
+		}
+	}
+}
+
+// remove closes sub's channel. Callers must hold h.mu.
+func (h *Hub) remove(sub *Subscription) {
+	if _, ok := h.subs[sub]; !ok {
+		return
+	}
+	delete(h.subs, sub)
+	close(sub.ch)
+}
+
+// GroupIDs returns the device group IDs of d, which the upstream sends as an
+// untyped list.
+func GroupIDs(d models.Device) []string {
+	list, ok := d.DeviceGroupsIDList.([]interface{})
+	if !ok {
+		return nil
+	}
+	ids := make([]string, 0, len(list))
+	for _, v := range list {
+		if id, ok := v.(string); ok {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
@@ -0,0 +1,72 @@
+package stream
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/alexbeattie/golangone/models"
+)
+
+// publishMoves publishes n position changes of one device, one event each.
+func publishMoves(h *Hub, n int) {
+	for i := 0; i < n; i++ {
+		h.Publish([]models.Device{{
+			DeviceID:          "d1",
+			LatestDevicePoint: models.DevicePoint{DevicePointID: fmt.Sprintf("p%d", i)},
+		}})
+	}
+}
+
+func TestSubscribeResume(t *testing.T) {
+	const epoch = 1767225600
+	id := func(seq int) string { return fmt.Sprintf("%d-%d", epoch, seq) }
+
+	tests := []struct {
+		name        string
+		lastEventID string
+		wantReplay  []string
+		wantErr     error
+	}{
+		{name: "no last event", lastEventID: ""},
+		{name: "up to date", lastEventID: id(5)},
+		{name: "inside the buffer", lastEventID: id(3), wantReplay: []string{id(4), id(5)}},
+		{name: "just before the oldest buffered event", lastEventID: id(2), wantReplay: []string{id(3), id(4), id(5)}},
+		{name: "past the buffer", lastEventID: id(1), wantErr: ErrResumeGap},
+		{name: "future ID", lastEventID: id(9), wantErr: ErrResumeGap},
+		{name: "previous epoch", lastEventID: fmt.Sprintf("%d-%d", epoch-1, 4), wantErr: ErrResumeGap},
+		{name: "malformed ID", lastEventID: "4", wantErr: ErrInvalidEventID},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewHub(3)
+			h.epoch = epoch
+			publishMoves(h, 5) // events 1-5; 3-5 stay buffered
+
+			sub, replay, err := h.Subscribe(tt.lastEventID, 1)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if errors.Is(err, ErrInvalidEventID) {
+				if sub != nil {
+					t.Error("got a subscription for a malformed ID")
+				}
+				return
+			}
+			if sub == nil {
+				t.Fatal("no subscription")
+			}
+			defer sub.Close()
+
+			var got []string
+			for _, ev := range replay {
+				got = append(got, ev.ID)
+			}
+			if !reflect.DeepEqual(got, tt.wantReplay) {
+				t.Errorf("replay = %v, want %v", got, tt.wantReplay)
+			}
+		})
+	}
+}